@@ -1,3 +1,8 @@
 module github.com/yesetoda/query_builder
 
 go 1.25.3
+
+require (
+	github.com/mattn/go-sqlite3 v1.14.33
+	gopkg.in/yaml.v3 v3.0.1
+)
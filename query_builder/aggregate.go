@@ -0,0 +1,188 @@
+package query_builder
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// projection is a single SELECT list entry: either a plain column or an aggregate.
+type projection struct {
+	Column    ColumnRef  // The projected column when Aggregate is nil
+	Aggregate *Aggregate // The projected aggregate, if any
+}
+
+// Aggregate represents an aggregate function applied to a column (e.g., "SUM(o.price) AS total").
+type Aggregate struct {
+	Func     string    // COUNT, SUM, AVG, MIN or MAX
+	Column   ColumnRef // The aggregated column; an empty ColumnName means * (COUNT only)
+	Distinct bool      // If true, renders FUNC(DISTINCT column)
+	Alias    string    // Output alias, required when projected
+}
+
+var allowedAggregates = map[string]bool{
+	"COUNT": true, "SUM": true, "AVG": true, "MIN": true, "MAX": true,
+}
+
+// CountAll returns a COUNT(*) aggregate.
+func CountAll() Aggregate {
+	return Aggregate{Func: "COUNT"}
+}
+
+// CountOf returns a COUNT(column) aggregate.
+func CountOf(ref string) Aggregate {
+	return Aggregate{Func: "COUNT", Column: Col(ref)}
+}
+
+// CountDistinct returns a COUNT(DISTINCT column) aggregate.
+func CountDistinct(ref string) Aggregate {
+	return Aggregate{Func: "COUNT", Column: Col(ref), Distinct: true}
+}
+
+// Sum returns a SUM(column) aggregate.
+func Sum(ref string) Aggregate {
+	return Aggregate{Func: "SUM", Column: Col(ref)}
+}
+
+// Avg returns an AVG(column) aggregate.
+func Avg(ref string) Aggregate {
+	return Aggregate{Func: "AVG", Column: Col(ref)}
+}
+
+// Min returns a MIN(column) aggregate.
+func Min(ref string) Aggregate {
+	return Aggregate{Func: "MIN", Column: Col(ref)}
+}
+
+// Max returns a MAX(column) aggregate.
+func Max(ref string) Aggregate {
+	return Aggregate{Func: "MAX", Column: Col(ref)}
+}
+
+// As returns a copy of the aggregate with the given output alias.
+func (a Aggregate) As(alias string) Aggregate {
+	a.Alias = alias
+	return a
+}
+
+// SelectAgg adds one or more aggregate projections.
+//
+// Each aggregate needs an alias, which HAVING and ORDER BY can use to refer to it.
+func (q *Query) SelectAgg(aggs ...Aggregate) *Query {
+	for _, a := range aggs {
+		agg := a
+		q.projections = append(q.projections, projection{Aggregate: &agg})
+	}
+	return q
+}
+
+// GroupBy appends one or more GROUP BY columns.
+//
+// Each entry is usually "alias.column".
+func (q *Query) GroupBy(columns ...string) *Query {
+	for _, col := range columns {
+		q.groupBy = append(q.groupBy, Col(col))
+	}
+	return q
+}
+
+// Having sets the root HAVING filter group.
+//
+// Filters may name a projected aggregate by its alias (e.g., F("total", ">", 100))
+// or a grouped column by "alias.column". Without GroupBy, HAVING applies to
+// the single group formed by the whole table, so an aggregate must be projected.
+func (q *Query) Having(group *FilterGroup) *Query {
+	q.having = group
+	return q
+}
+
+// aggregateByAlias returns the projected aggregate whose output alias is ref's column name.
+func (q *Query) aggregateByAlias(ref ColumnRef) (Aggregate, bool) {
+	if ref.TableAlias != "" {
+		return Aggregate{}, false
+	}
+	for _, p := range q.projections {
+		if p.Aggregate != nil && p.Aggregate.Alias == ref.ColumnName {
+			return *p.Aggregate, true
+		}
+	}
+	return Aggregate{}, false
+}
+
+// aggregateAliases maps every projected aggregate's output alias to the aggregate.
+func (q *Query) aggregateAliases() map[string]Aggregate {
+	aggs := make(map[string]Aggregate)
+	for _, p := range q.projections {
+		if p.Aggregate != nil {
+			aggs[p.Aggregate.Alias] = *p.Aggregate
+		}
+	}
+	return aggs
+}
+
+// renderAggregate validates an aggregate and renders its expression without the alias.
+func (q *Query) renderAggregate(a Aggregate, aliasMap map[string]string, schema map[string]map[string]bool) (string, error) {
+	fn := strings.ToUpper(a.Func)
+	if !allowedAggregates[fn] {
+		return "", fmt.Errorf("invalid aggregate function: %s", a.Func)
+	}
+	if a.Alias == "" {
+		return "", fmt.Errorf("aggregate alias required: %s", fn)
+	}
+	if a.Column.ColumnName == "" || a.Column.ColumnName == "*" {
+		if fn != "COUNT" || a.Distinct {
+			return "", fmt.Errorf("invalid aggregate: %s(*)", fn)
+		}
+		return "COUNT(*)", nil
+	}
+	if err := q.validateCol(a.Column, aliasMap, schema); err != nil {
+		return "", fmt.Errorf("invalid aggregate column: %v", err)
+	}
+	if a.Distinct {
+		return fmt.Sprintf("%s(DISTINCT %s)", fn, q.quoteCol(a.Column)), nil
+	}
+	return fmt.Sprintf("%s(%s)", fn, q.quoteCol(a.Column)), nil
+}
+
+// buildGroupBy generates the GROUP BY and HAVING clauses with validation.
+func (q *Query) buildGroupBy(sb *strings.Builder, args *[]interface{}, aliasMap map[string]string, schema map[string]map[string]bool) error {
+	grouped := make(map[ColumnRef]bool)
+	var parts []string
+	for _, c := range q.groupBy {
+		if err := q.validateCol(c, aliasMap, schema); err != nil {
+			return fmt.Errorf("invalid group column: %v", err)
+		}
+		grouped[c] = true
+		parts = append(parts, q.quoteCol(c))
+	}
+
+	// Once anything is grouped or aggregated, every plain projection must be
+	// grouped, or the statement is rejected by strict databases.
+	if !q.isCount && (len(q.groupBy) > 0 || len(q.aggregateAliases()) > 0) {
+		for _, p := range q.projections {
+			if p.Aggregate == nil && !grouped[p.Column] {
+				return fmt.Errorf("column must appear in GROUP BY: %s.%s", p.Column.TableAlias, p.Column.ColumnName)
+			}
+		}
+	}
+
+	if len(q.groupBy) > 0 {
+		sb.WriteString(" GROUP BY " + strings.Join(parts, ", "))
+	}
+
+	if q.having == nil {
+		return nil
+	}
+	aggs := q.aggregateAliases()
+	if len(q.groupBy) == 0 && len(aggs) == 0 {
+		return errors.New("HAVING requires GROUP BY or an aggregate projection")
+	}
+	havingClause, err := q.buildFilterGroup(*q.having, args, aliasMap, 0, schema, aggs)
+	if err != nil {
+		return err
+	}
+	if havingClause != "" {
+		sb.WriteString(" HAVING " + havingClause)
+	}
+	return nil
+}
@@ -0,0 +1,55 @@
+package query_builder
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestAggregates(t *testing.T) {
+	orders := func() *Query { return New(PostgresDialect{}).From("orders", "o") }
+	tests := []struct {
+		name    string
+		q       *Query
+		want    string
+		args    []interface{}
+		wantErr string
+	}{
+		{"group and having",
+			orders().Select("o.user_id").SelectAgg(Sum("o.price").As("total"), CountDistinct("o.product_id").As("products")).
+				GroupBy("o.user_id").Having(And(F("total", ">", 100))).OrderBy("total", "DESC"),
+			`SELECT "o"."user_id", SUM("o"."price") AS "total", COUNT(DISTINCT "o"."product_id") AS "products" FROM "orders" "o" GROUP BY "o"."user_id" HAVING SUM("o"."price") > $1 ORDER BY "total" DESC`,
+			[]interface{}{100}, ""},
+		{"having without group by",
+			orders().SelectAgg(Sum("o.price").As("t")).Having(And(F("t", ">", 100))),
+			`SELECT SUM("o"."price") AS "t" FROM "orders" "o" HAVING SUM("o"."price") > $1`,
+			[]interface{}{100}, ""},
+		{"count star", orders().SelectAgg(CountAll().As("n")).Where(And(F("o.paid", "=", true))),
+			`SELECT COUNT(*) AS "n" FROM "orders" "o" WHERE "o"."paid" = $1`, []interface{}{true}, ""},
+		{"having without aggregates", orders().Select("o.id").Having(And(F("o.id", ">", 1))), "", nil, "HAVING requires GROUP BY or an aggregate projection"},
+		{"ungrouped column", orders().Select("o.user_id").SelectAgg(Sum("o.price").As("t")), "", nil, "column must appear in GROUP BY: o.user_id"},
+		{"missing alias", orders().SelectAgg(Sum("o.price")), "", nil, "aggregate alias required: SUM"},
+		{"sum star", orders().SelectAgg(Aggregate{Func: "SUM", Alias: "s"}), "", nil, "invalid aggregate: SUM(*)"},
+		{"unknown function", orders().SelectAgg(Aggregate{Func: "MEDIAN", Column: Col("o.price"), Alias: "m"}), "", nil, "invalid aggregate function: MEDIAN"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sql, args, err := tt.q.Build()
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("Build error = %v, want %q; sql = %s", err, tt.wantErr, sql)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if sql != tt.want {
+				t.Errorf("sql =\n%s\nwant\n%s", sql, tt.want)
+			}
+			if !reflect.DeepEqual(args, tt.args) {
+				t.Errorf("args = %#v, want %#v", args, tt.args)
+			}
+		})
+	}
+}
@@ -3,6 +3,7 @@ package query_builder
 import (
 	"errors"
 	"fmt"
+	"reflect"
 	"strings"
 )
 
@@ -17,20 +18,26 @@ type Dialect interface {
 	Placeholder(index int) string
 	// QuoteIdentifier wraps a table or column name with the appropriate quotes for the dialect.
 	// For Postgres/Oracle, this uses double quotes (""); for MySQL, it uses backticks (``).
+	// Quote characters embedded in name must be escaped so the result is always a single identifier.
 	QuoteIdentifier(name string) string
 }
 
 // PostgresDialect implements Dialect for PostgreSQL, using $1, $2 placeholders and double quotes.
-type PostgresDialect struct{}
+type PostgresDialect struct {
+	// AnyArray renders IN and NOT IN over a slice as "= ANY($1)" and "<> ALL($1)",
+	// binding the whole slice as one array parameter. The driver must accept the
+	// slice as an array, for example through pq.Array.
+	AnyArray bool
+}
 
 // Placeholder returns $1, $2, etc.
 func (p PostgresDialect) Placeholder(index int) string {
 	return fmt.Sprintf("$%d", index)
 }
 
-// QuoteIdentifier returns "name".
+// QuoteIdentifier returns "name", doubling any embedded double quotes.
 func (p PostgresDialect) QuoteIdentifier(name string) string {
-	return fmt.Sprintf("\"%s\"", name)
+	return fmt.Sprintf("\"%s\"", strings.ReplaceAll(name, "\"", "\"\""))
 }
 
 // MySQLDialect implements Dialect for MySQL, using ? placeholders and backticks.
@@ -41,9 +48,9 @@ func (m MySQLDialect) Placeholder(index int) string {
 	return "?"
 }
 
-// QuoteIdentifier returns `name`.
+// QuoteIdentifier returns `name`, doubling any embedded backticks.
 func (m MySQLDialect) QuoteIdentifier(name string) string {
-	return fmt.Sprintf("`%s`", name)
+	return fmt.Sprintf("`%s`", strings.ReplaceAll(name, "`", "``"))
 }
 
 // OracleDialect implements Dialect for Oracle, using :1, :2 placeholders and modern pagination.
@@ -54,9 +61,9 @@ func (o OracleDialect) Placeholder(index int) string {
 	return fmt.Sprintf(":%d", index)
 }
 
-// QuoteIdentifier returns "name".
+// QuoteIdentifier returns "name", doubling any embedded double quotes.
 func (o OracleDialect) QuoteIdentifier(name string) string {
-	return fmt.Sprintf("\"%s\"", name)
+	return fmt.Sprintf("\"%s\"", strings.ReplaceAll(name, "\"", "\"\""))
 }
 
 // Query builds a SQL SELECT or COUNT statement.
@@ -64,19 +71,29 @@ func (o OracleDialect) QuoteIdentifier(name string) string {
 // A Query is configured through chainable methods and rendered with Build.
 // When WithSchema is set, table and column references are validated.
 type Query struct {
-	dialect       Dialect                    // The target SQL dialect (Postgres, MySQL, Oracle)
+	dialect       Dialect                    // The target SQL dialect (Postgres, MySQL, Oracle, SQLite)
 	allowedSchema map[string]map[string]bool // Validation schema: map[table]map[column]bool
+	typedSchema   Schema                     // Column types and constraints, set by WithTypedSchema
 	baseTable     string                     // The main table to select from
 	baseAlias     string                     // Alias for the base table
-	projections   []ColumnRef                // List of columns to SELECT
+	projections   []projection               // List of columns and aggregates to SELECT
 	joins         []Join                     // List of JOIN clauses
 	where         *FilterGroup               // Root filter group (WHERE clause)
+	ctes          []commonTable              // Common table expressions rendered in a WITH prefix
+	groupBy       []ColumnRef                // List of columns to GROUP BY
+	having        *FilterGroup               // Root filter group (HAVING clause)
 	sorts         []Sort                     // List of columns to ORDER BY
 	limit         int                        // Maximum rows to fetch
 	offset        int                        // Rows to skip (if using Offset pagination)
 	pagination    Pagination                 // Detailed pagination configuration
+	uniqueColumns map[string]map[string]bool // Unique columns: map[table]map[column]bool, used by keyset pagination
+	cursorCodec   *CursorCodec               // Codec that verifies and decodes AfterCursor tokens
+	fieldMap      FieldMap                   // Public field names accepted by FF, SelectFields and OrderByField
 	isCount       bool                       // If true, generates SELECT COUNT(*)
+	countAlias    bool                       // If true, COUNT(*) is named "count", for CTE bodies and set parts
+	unquoted      bool                       // If true, identifiers are rendered without dialect quoting
 	errors        []error                    // Collection of errors encountered during building
+	nesting       int                        // Subquery nesting level, to stop self-referencing queries
 }
 
 // ColumnRef represents a reference to a table column, optionally with a table alias.
@@ -113,7 +130,7 @@ type JoinCondition struct {
 
 // FilterGroup combines filters and nested groups with a logical operator.
 type FilterGroup struct {
-	Operator string        // "AND" or "OR"
+	Operator string        // "AND", "OR", or "NOT" (which wraps exactly one item)
 	Filters  []Filter      // Individual comparisons
 	Groups   []FilterGroup // Nested groups
 }
@@ -151,8 +168,11 @@ func createGroup(op string, items ...interface{}) *FilterGroup {
 // Filter represents a single comparison in a WHERE clause (e.g., "age > 18").
 type Filter struct {
 	Column ColumnRef   // The column to filter on
-	Op     string      // The operator (e.g., "=", ">", "LIKE", "IN")
-	Value  interface{} // The value to compare against (will be parameterized)
+	Op     string      // The operator (e.g., "=", ">", "LIKE", "IN", "BETWEEN", "IS NULL")
+	Value  interface{} // The value to compare against (will be parameterized, or rendered inline if a *Query or ColumnRef); nil for IS NULL
+	Field  string      // Public field name resolved through the query's FieldMap; replaces Column when set
+
+	clientOp string // Operator before Normalize negated it, checked against the field's Operators
 }
 
 // F constructs a single Filter.
@@ -162,21 +182,38 @@ func F(ref string, op string, val interface{}) Filter {
 	return Filter{Column: Col(ref), Op: op, Value: val}
 }
 
+// FC constructs a Filter comparing two columns, e.g. FC("o.updated_at", ">", "o.created_at").
+//
+// Both refs may be either "alias.column" or just "column". The right side is
+// validated and quoted like the left instead of being bound as a value.
+func FC(left string, op string, right string) Filter {
+	return Filter{Column: Col(left), Op: op, Value: Col(right)}
+}
+
 // Sort represents a single column ordering in the ORDER BY clause.
 type Sort struct {
 	Column ColumnRef // The column to sort by
 	Dir    string    // Sort direction: "ASC" or "DESC"
+	Nulls  string    // Placement of NULLs: "FIRST", "LAST", or "" for the database default
 }
 
 // Pagination configures how results should be limited and paged.
 type Pagination struct {
 	Type     string                 // "offset" (standard) or "keyset" (cursor-based)
 	LastSeen map[string]interface{} // Values of sorting columns from the last page (for Keyset)
+	Cursor   string                 // Opaque cursor token decoded into LastSeen at build time (for Keyset)
+	Backward bool                   // If true, fetches the page before LastSeen instead of after it (for Keyset)
 }
 
 // Internal allow-lists for operators, join types, and sort directions.
-var allowedOperators = map[string]bool{
-	"=": true, "!=": true, ">": true, "<": true, ">=": true, "<=": true, "IN": true, "LIKE": true, "IS": true, "IS NOT": true,
+//
+// allowedOperators maps each operator to the number of values it binds.
+var allowedOperators = map[string]int{
+	"=": arityOne, "!=": arityOne, "<>": arityOne, ">": arityOne, "<": arityOne, ">=": arityOne, "<=": arityOne,
+	"LIKE": arityOne, "NOT LIKE": arityOne, "ILIKE": arityOne, "NOT ILIKE": arityOne,
+	"IN": arityList, "NOT IN": arityList,
+	"BETWEEN": arityTwo, "NOT BETWEEN": arityTwo,
+	"IS": arityNone, "IS NOT": arityNone, "IS NULL": arityNone, "IS NOT NULL": arityNone,
 }
 
 var allowedJoinTypes = map[string]bool{
@@ -203,6 +240,17 @@ func (q *Query) WithSchema(schema map[string]map[string]bool) *Query {
 	return q
 }
 
+// UnquotedIdentifiers disables dialect quoting of tables, aliases and columns.
+//
+// Identifiers are then rendered exactly as given, which keeps the database's
+// case folding rules in effect. Because raw identifiers are written into the
+// SQL text, this mode should be combined with WithSchema when any identifier
+// can come from untrusted input.
+func (q *Query) UnquotedIdentifiers() *Query {
+	q.unquoted = true
+	return q
+}
+
 // From sets the primary table and its alias for the query.
 // Example: From("users", "u")
 func (q *Query) From(table string, alias string) *Query {
@@ -213,10 +261,11 @@ func (q *Query) From(table string, alias string) *Query {
 
 // Select adds one or more projection columns.
 //
-// Each entry is usually "alias.column".
+// Each entry is usually "alias.column"; "alias.*" selects every column of
+// one table.
 func (q *Query) Select(columns ...string) *Query {
 	for _, col := range columns {
-		q.projections = append(q.projections, Col(col))
+		q.projections = append(q.projections, projection{Column: Col(col)})
 	}
 	return q
 }
@@ -232,7 +281,8 @@ func (q *Query) Count() *Query {
 // Join adds a JOIN clause.
 //
 // joinType must be one of INNER, LEFT, RIGHT, FULL, or CROSS.
-// left and right are column references used in the ON condition.
+// left and right are column references used in the ON condition, compared
+// with op, which must be an operator that takes a single value, such as "=".
 func (q *Query) Join(joinType, table, alias, left, right, op string) *Query {
 	q.joins = append(q.joins, Join{
 		Type:  joinType,
@@ -255,30 +305,33 @@ func (q *Query) Where(group *FilterGroup) *Query {
 	return q
 }
 
-// Eq appends an equality filter to the current WHERE group.
+// Eq adds an equality filter that every row must match.
 //
-// If no WHERE group exists, Eq creates an AND group first.
+// The filter is appended to a root AND group; any other root, such as OR or
+// NOT, is combined with it in a new AND group.
 func (q *Query) Eq(ref string, val interface{}) *Query {
-	filter := F(ref, "=", val)
-	if q.where == nil {
-		q.where = And(filter)
-	} else {
-		q.where.Filters = append(q.where.Filters, filter)
-	}
+	q.addFilter(F(ref, "=", val))
 	return q
 }
 
-// In appends an IN filter to the current WHERE group.
+// In adds an IN filter that every row must match, combined as by Eq.
 //
-// val should be a slice value compatible with your SQL driver.
+// val is usually a slice; each element is bound as its own placeholder.
 func (q *Query) In(ref string, val interface{}) *Query {
-	filter := F(ref, "IN", val)
-	if q.where == nil {
+	q.addFilter(F(ref, "IN", val))
+	return q
+}
+
+// addFilter ANDs filter onto the WHERE tree.
+func (q *Query) addFilter(filter Filter) {
+	switch {
+	case q.where == nil:
 		q.where = And(filter)
-	} else {
+	case strings.EqualFold(q.where.Operator, "AND"):
 		q.where.Filters = append(q.where.Filters, filter)
+	default:
+		q.where = And(q.where, filter)
 	}
-	return q
 }
 
 // OrderBy appends a sort column and direction.
@@ -289,6 +342,15 @@ func (q *Query) OrderBy(column string, dir string) *Query {
 	return q
 }
 
+// OrderByNulls appends a sort column and direction with explicit NULL placement.
+//
+// nulls should be FIRST or LAST. Dialects without NULLS FIRST/LAST get an
+// equivalent CASE expression sorted ahead of the column.
+func (q *Query) OrderByNulls(column, dir, nulls string) *Query {
+	q.sorts = append(q.sorts, Sort{Column: Col(column), Dir: strings.ToUpper(dir), Nulls: strings.ToUpper(nulls)})
+	return q
+}
+
 // Limit sets the maximum number of rows to return.
 func (q *Query) Limit(limit int) *Query {
 	q.limit = limit
@@ -306,7 +368,8 @@ func (q *Query) Offset(offset int) *Query {
 
 // KeysetPagination configures cursor-based paging.
 //
-// lastSeen keys must use the "alias.column" form and match sort columns.
+// lastSeen keys must use the "alias.column" form and provide a value for every
+// sort column. A nil or empty lastSeen requests the first page.
 func (q *Query) KeysetPagination(lastSeen map[string]interface{}) *Query {
 	q.pagination = Pagination{
 		Type:     "keyset",
@@ -319,60 +382,95 @@ func (q *Query) KeysetPagination(lastSeen map[string]interface{}) *Query {
 //
 // Build validates table and column references when schema validation is enabled.
 func (q *Query) Build() (string, []interface{}, error) {
-	if len(q.errors) > 0 {
-		return "", nil, q.errors[0]
+	if err := checkDialect(q.dialect); err != nil {
+		return "", nil, err
 	}
-	// Basic sanity check on the base table.
-	if err := q.validateBase(q.allowedSchema); err != nil {
+	var args []interface{}
+	sql, err := q.build(&args, nil)
+	if err != nil {
+		return "", nil, err
+	}
+	if err := checkParamLimit(q.dialect, args); err != nil {
 		return "", nil, err
 	}
+	return sql, args, nil
+}
+
+// build renders the statement, appending bound arguments to args so placeholder
+// numbering continues from any enclosing statement.
+//
+// outer maps the aliases of enclosing queries, which correlated references may use.
+func (q *Query) build(args *[]interface{}, outer map[string]string) (string, error) {
+	if len(q.errors) > 0 {
+		return "", q.errors[0]
+	}
 
 	var sb strings.Builder
-	var args []interface{}
+
+	// 0. WITH phase; each CTE becomes a virtual table in the validation schema.
+	schema, err := q.buildCTEs(&sb, args, q.allowedSchema)
+	if err != nil {
+		return "", err
+	}
+
+	// Basic sanity check on the base table.
+	if err := q.validateBase(schema); err != nil {
+		return "", err
+	}
 
 	// Register all table aliases to ensure visibility during column validation.
-	aliasMap, err := q.registerAliases()
+	aliasMap, err := q.registerAliases(outer)
 	if err != nil {
-		return "", nil, err
+		return "", err
 	}
 
 	// 1. SELECT phase
 	if q.isCount {
 		sb.WriteString("SELECT COUNT(*)")
+		if q.countAlias {
+			sb.WriteString(" AS " + q.quoteIdent("count"))
+		}
 	} else {
-		if err := q.buildProjections(&sb, aliasMap, q.allowedSchema, q.getBaseAlias()); err != nil {
-			return "", nil, err
+		sb.WriteString("SELECT ")
+		q.buildTop(&sb, args)
+		if err := q.buildProjections(&sb, aliasMap, schema, q.getBaseAlias()); err != nil {
+			return "", err
 		}
 	}
 
 	// 2. FROM phase
-	sb.WriteString(fmt.Sprintf(" FROM %s %s", q.baseTable, q.getBaseAlias()))
+	sb.WriteString(fmt.Sprintf(" FROM %s %s", q.quoteIdent(q.baseTable), q.quoteIdent(q.getBaseAlias())))
 
 	// 3. JOIN phase
-	if err := q.buildJoins(&sb, aliasMap, q.allowedSchema); err != nil {
-		return "", nil, err
+	if err := q.buildJoins(&sb, aliasMap, schema); err != nil {
+		return "", err
 	}
 
 	// 4. WHERE phase (includes standard filters and Keyset pagination filters)
-	if err := q.buildFilters(&sb, &args, aliasMap, q.allowedSchema); err != nil {
-		return "", nil, err
+	if err := q.buildFilters(&sb, args, aliasMap, schema); err != nil {
+		return "", err
+	}
+
+	// 5. GROUP BY and HAVING phase
+	if err := q.buildGroupBy(&sb, args, aliasMap, schema); err != nil {
+		return "", err
 	}
 
-	// Count queries generally finalize after the WHERE clause.
+	// Count queries generally finalize after the WHERE and GROUP BY clauses.
 	// so no need to build the order and also the pagination
 	if q.isCount {
-		return sb.String(), args, nil
+		return sb.String(), nil
 	}
 
-	// 5. ORDER BY phase
-	if err := q.buildOrderBy(&sb, aliasMap, q.allowedSchema); err != nil {
-		return "", nil, err
+	// 6. ORDER BY phase
+	if err := q.buildOrderBy(&sb, aliasMap, schema); err != nil {
+		return "", err
 	}
 
-	// 6. LIMIT/OFFSET phase (Dialect-specific syntax)
-	q.buildLimitOffset(&sb, &args)
+	// 7. LIMIT/OFFSET phase (Dialect-specific syntax)
+	q.buildLimitOffset(&sb, args)
 
-	return sb.String(), args, nil
+	return sb.String(), nil
 }
 
 // validateBase ensures a primary table is selected and exists in the schema.
@@ -396,8 +494,26 @@ func (q *Query) getBaseAlias() string {
 	return q.baseTable
 }
 
+// quoteIdent quotes a single identifier through the dialect unless quoting is disabled.
+func (q *Query) quoteIdent(name string) string {
+	if q.unquoted {
+		return name
+	}
+	return q.dialect.QuoteIdentifier(name)
+}
+
+// quoteCol renders a column reference as alias.column, quoting each part.
+func (q *Query) quoteCol(ref ColumnRef) string {
+	if ref.TableAlias == "" {
+		return q.quoteIdent(ref.ColumnName)
+	}
+	return q.quoteIdent(ref.TableAlias) + "." + q.quoteIdent(ref.ColumnName)
+}
+
 // registerAliases creates a mapping of alias -> tableName for validation.
-func (q *Query) registerAliases() (map[string]string, error) {
+//
+// Aliases from outer are added last, so the query's own aliases shadow them.
+func (q *Query) registerAliases(outer map[string]string) (map[string]string, error) {
 	aliasMap := make(map[string]string)
 	aliasMap[q.getBaseAlias()] = q.baseTable
 
@@ -410,38 +526,39 @@ func (q *Query) registerAliases() (map[string]string, error) {
 		}
 		aliasMap[j.Alias] = j.Table
 	}
+	for alias, table := range outer {
+		if _, exists := aliasMap[alias]; !exists {
+			aliasMap[alias] = table
+		}
+	}
 	return aliasMap, nil
 }
 
 // buildFilters translates the filter tree into a SQL WHERE clause.
 func (q *Query) buildFilters(sb *strings.Builder, args *[]interface{}, aliasMap map[string]string, schema map[string]map[string]bool) error {
-	hasWhere := false
+	whereClause := ""
 	if q.where != nil {
-		whereClause, err := q.buildFilterGroup(*q.where, args, aliasMap, 0, schema)
+		clause, err := q.buildFilterGroup(*q.where, args, aliasMap, 0, schema, nil)
 		if err != nil {
 			return err
 		}
-		if whereClause != "" {
-			sb.WriteString(" WHERE ")
-			sb.WriteString(whereClause)
-			hasWhere = true
-		}
+		whereClause = clause
 	}
 
 	// Append Keyset constraints if applicable.
-	if q.pagination.Type == "keyset" && len(q.sorts) > 0 {
-		keysetClause, err := q.buildKeysetPagination(args, hasWhere)
-		if err != nil {
-			return err
-		}
-		if keysetClause != "" {
-			if !hasWhere {
-				sb.WriteString(" WHERE ")
-			} else {
-				sb.WriteString(" AND ")
-			}
-			sb.WriteString(keysetClause)
-		}
+	keysetClause, err := q.buildKeysetPagination(args, aliasMap, schema)
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case whereClause != "" && keysetClause != "":
+		// Parenthesize the filters so a top-level OR cannot absorb the keyset bound.
+		sb.WriteString(" WHERE (" + whereClause + ") AND " + keysetClause)
+	case whereClause != "":
+		sb.WriteString(" WHERE " + whereClause)
+	case keysetClause != "":
+		sb.WriteString(" WHERE " + keysetClause)
 	}
 	return nil
 }
@@ -454,67 +571,116 @@ func (q *Query) buildOrderBy(sb *strings.Builder, aliasMap map[string]string, sc
 	sb.WriteString(" ORDER BY ")
 	var sortParts []string
 	for _, s := range q.sorts {
-		if err := q.validateCol(s.Column, aliasMap, schema); err != nil {
-			return fmt.Errorf("invalid sort column: %v", err)
-		}
 		dir := strings.ToUpper(s.Dir)
 		if !allowedSortDir[dir] {
 			return fmt.Errorf("invalid sort direction: %s", s.Dir)
 		}
-		sortParts = append(sortParts, fmt.Sprintf("%s.%s %s", s.Column.TableAlias, s.Column.ColumnName, dir))
+		nulls := strings.ToUpper(s.Nulls)
+		if nulls != "" && nulls != "FIRST" && nulls != "LAST" {
+			return fmt.Errorf("invalid nulls placement: %s", s.Nulls)
+		}
+		// Backward keyset pages are fetched in reverse and flipped by the caller.
+		if q.IsBackward() {
+			dir = reverseDir(dir)
+		}
+		// Aggregates are sorted by their output alias.
+		if _, ok := q.aggregateByAlias(s.Column); ok {
+			sortParts = append(sortParts, q.sortExpr(q.quoteIdent(s.Column.ColumnName), dir, nulls)...)
+			continue
+		}
+		if err := q.validateCol(s.Column, aliasMap, schema); err != nil {
+			return fmt.Errorf("invalid sort column: %v", err)
+		}
+		sortParts = append(sortParts, q.sortExpr(q.quoteCol(s.Column), dir, nulls)...)
 	}
 	sb.WriteString(strings.Join(sortParts, ", "))
 	return nil
 }
 
-// buildLimitOffset adds pagination clauses using standard or dialect-specific (Oracle) syntax.
+// sortExpr renders one ORDER BY entry, emulating NULLS FIRST/LAST with a CASE
+// expression when the dialect lacks them.
+func (q *Query) sortExpr(col, dir, nulls string) []string {
+	switch {
+	case nulls == "":
+		return []string{fmt.Sprintf("%s %s", col, dir)}
+	case q.caps().NullsOrdering:
+		return []string{fmt.Sprintf("%s %s NULLS %s", col, dir, nulls)}
+	case nulls == "FIRST":
+		return []string{fmt.Sprintf("CASE WHEN %s IS NULL THEN 0 ELSE 1 END", col), fmt.Sprintf("%s %s", col, dir)}
+	}
+	return []string{fmt.Sprintf("CASE WHEN %s IS NULL THEN 1 ELSE 0 END", col), fmt.Sprintf("%s %s", col, dir)}
+}
+
+// buildLimitOffset adds pagination clauses in the dialect's pagination style.
 func (q *Query) buildLimitOffset(sb *strings.Builder, args *[]interface{}) {
-	if q.limit <= 0 {
+	if q.usesTop() {
 		return
 	}
-	// Use FETCH NEXT ... syntax for Oracle or Keyset-based paging.
-	if q.pagination.Type == "keyset" || q.dialect.Placeholder(1) == ":1" {
-		*args = append(*args, q.limit)
-		sb.WriteString(fmt.Sprintf(" FETCH NEXT %s ROWS ONLY", q.dialect.Placeholder(len(*args))))
-	} else {
-		*args = append(*args, q.limit)
-		sb.WriteString(fmt.Sprintf(" LIMIT %s", q.dialect.Placeholder(len(*args))))
-		if q.offset > 0 {
-			*args = append(*args, q.offset)
-			sb.WriteString(fmt.Sprintf(" OFFSET %s", q.dialect.Placeholder(len(*args))))
-		}
+	q.writeLimitOffset(sb, args, q.limit, q.pageOffset(), len(q.sorts) > 0)
+}
+
+// pageOffset returns the rows to skip; keyset pages are bounded by their
+// WHERE predicate instead, so they never skip rows.
+func (q *Query) pageOffset() int {
+	if q.pagination.Type == "keyset" {
+		return 0
 	}
+	return q.offset
 }
 
 // buildProjections generates the SELECT column list.
 func (q *Query) buildProjections(sb *strings.Builder, aliasMap map[string]string, schema map[string]map[string]bool, baseAlias string) error {
-	sb.WriteString("SELECT ")
 	if len(q.projections) == 0 {
-		sb.WriteString(baseAlias + ".*")
+		sb.WriteString(q.quoteIdent(baseAlias) + ".*")
 	} else {
 		var cols []string
 		for _, p := range q.projections {
-			if err := q.validateCol(p, aliasMap, schema); err != nil {
+			if p.Aggregate != nil {
+				expr, err := q.renderAggregate(*p.Aggregate, aliasMap, schema)
+				if err != nil {
+					return err
+				}
+				cols = append(cols, fmt.Sprintf("%s AS %s", expr, q.quoteIdent(p.Aggregate.Alias)))
+				continue
+			}
+			if p.Column.ColumnName == "*" {
+				col, err := q.renderStar(p.Column, aliasMap, schema)
+				if err != nil {
+					return err
+				}
+				cols = append(cols, col)
+				continue
+			}
+			if err := q.validateCol(p.Column, aliasMap, schema); err != nil {
 				return fmt.Errorf("invalid column: %v", err)
 			}
-			cols = append(cols, fmt.Sprintf("%s.%s", p.TableAlias, p.ColumnName))
+			cols = append(cols, q.quoteCol(p.Column))
 		}
 		sb.WriteString(strings.Join(cols, ", "))
 	}
 	return nil
 }
 
+// renderStar renders an "alias.*" or bare "*" projection, leaving the * unquoted.
+func (q *Query) renderStar(ref ColumnRef, aliasMap map[string]string, schema map[string]map[string]bool) (string, error) {
+	if ref.TableAlias == "" {
+		return "*", nil
+	}
+	if _, ok := aliasMap[ref.TableAlias]; schema != nil && !ok {
+		return "", fmt.Errorf("invalid column: %s.*", ref.TableAlias)
+	}
+	return q.quoteIdent(ref.TableAlias) + ".*", nil
+}
+
 // buildJoins iteratively builds all JOIN clauses.
 func (q *Query) buildJoins(sb *strings.Builder, aliasMap map[string]string, schema map[string]map[string]bool) error {
 	for _, j := range q.joins {
 		if err := q.validateJoin(j, aliasMap, schema); err != nil {
 			return err
 		}
-		sb.WriteString(fmt.Sprintf(" %s JOIN %s %s ON %s.%s %s %s.%s",
-			strings.ToUpper(j.Type), j.Table, j.Alias,
-			j.Condition.Left.TableAlias, j.Condition.Left.ColumnName,
-			j.Condition.Op,
-			j.Condition.Right.TableAlias, j.Condition.Right.ColumnName,
+		sb.WriteString(fmt.Sprintf(" %s JOIN %s %s ON %s",
+			strings.ToUpper(j.Type), q.quoteIdent(j.Table), q.quoteIdent(j.Alias),
+			q.renderBinary(q.quoteCol(j.Condition.Left), strings.ToUpper(j.Condition.Op), q.quoteCol(j.Condition.Right)),
 		))
 	}
 	return nil
@@ -525,6 +691,13 @@ func (q *Query) validateJoin(j Join, aliasMap map[string]string, schema map[stri
 	if !allowedJoinTypes[strings.ToUpper(j.Type)] {
 		return fmt.Errorf("invalid join type: %s", j.Type)
 	}
+	if !supportsJoin(q.dialect, j.Type) {
+		return fmt.Errorf("join type not supported by dialect %T: %s", q.dialect, j.Type)
+	}
+	// The ON operator is written into the SQL, so only single-value comparisons are allowed.
+	if allowedOperators[strings.ToUpper(j.Condition.Op)] != arityOne {
+		return fmt.Errorf("invalid join operator: %s", j.Condition.Op)
+	}
 	if schema == nil {
 		return nil
 	}
@@ -553,22 +726,27 @@ func (q *Query) validateCol(ref ColumnRef, aliasMap map[string]string, schema ma
 }
 
 // buildFilterGroup recursively builds nested AND/OR groups.
-func (q *Query) buildFilterGroup(g FilterGroup, args *[]interface{}, aliasMap map[string]string, depth int, schema map[string]map[string]bool) (string, error) {
+//
+// aggs holds the aggregates a HAVING clause may reference by alias; it is nil for WHERE.
+func (q *Query) buildFilterGroup(g FilterGroup, args *[]interface{}, aliasMap map[string]string, depth int, schema map[string]map[string]bool, aggs map[string]Aggregate) (string, error) {
 	if depth > maxFilterDepth {
 		return "", errors.New("filter depth exceeded")
 	}
 	op := strings.ToUpper(g.Operator)
+	if op == "NOT" {
+		return q.buildNot(g, args, aliasMap, depth, schema, aggs)
+	}
 	if op != "AND" && op != "OR" {
 		return "", errors.New("invalid logical operator")
 	}
 
-	parts, err := q.collectFilters(g.Filters, args, aliasMap, schema)
+	parts, err := q.collectFilters(g.Filters, args, aliasMap, schema, aggs)
 	if err != nil {
 		return "", err
 	}
 
 	for _, subGroup := range g.Groups {
-		sub, err := q.buildFilterGroup(subGroup, args, aliasMap, depth+1, schema)
+		sub, err := q.buildFilterGroup(subGroup, args, aliasMap, depth+1, schema, aggs)
 		if err != nil {
 			return "", err
 		}
@@ -584,45 +762,100 @@ func (q *Query) buildFilterGroup(g FilterGroup, args *[]interface{}, aliasMap ma
 }
 
 // collectFilters validates and parameterizes individual filters in a group.
-func (q *Query) collectFilters(filters []Filter, args *[]interface{}, aliasMap map[string]string, schema map[string]map[string]bool) ([]string, error) {
+func (q *Query) collectFilters(filters []Filter, args *[]interface{}, aliasMap map[string]string, schema map[string]map[string]bool, aggs map[string]Aggregate) ([]string, error) {
 	var parts []string
 	for _, f := range filters {
-		if err := q.validateCol(f.Column, aliasMap, schema); err != nil {
-			return nil, fmt.Errorf("invalid column: %v", err)
-		}
-		if !allowedOperators[strings.ToUpper(f.Op)] {
-			return nil, fmt.Errorf("invalid operator: %s", f.Op)
+		if f.Field == "" {
+			part, err := q.renderFilter(f, args, aliasMap, schema, aggs)
+			if err != nil {
+				return nil, err
+			}
+			parts = append(parts, part)
+			continue
 		}
 
-		*args = append(*args, f.Value)
-		parts = append(parts, fmt.Sprintf("%s.%s %s %s",
-			f.Column.TableAlias,
-			f.Column.ColumnName,
-			f.Op,
-			q.dialect.Placeholder(len(*args)),
-		))
+		resolved, err := q.resolveField(f)
+		if err != nil {
+			return nil, err
+		}
+		part, err := q.renderFilter(resolved, args, aliasMap, schema, aggs)
+		if err != nil {
+			return nil, fieldFilterError(f.Field, err)
+		}
+		parts = append(parts, part)
 	}
 	return parts, nil
 }
 
-// buildKeysetPagination generates the cursor-based comparison for paging.
-func (q *Query) buildKeysetPagination(args *[]interface{}, hasWhere bool) (string, error) {
-	if q.pagination.Type != "keyset" || len(q.sorts) == 0 {
-		return "", nil
+// renderFilter validates and parameterizes a single filter.
+func (q *Query) renderFilter(f Filter, args *[]interface{}, aliasMap map[string]string, schema map[string]map[string]bool, aggs map[string]Aggregate) (string, error) {
+	// EXISTS and NOT EXISTS have no left-hand column.
+	if sub, ok := f.Value.(*Query); ok && f.Column.ColumnName == "" {
+		return q.buildExists(f.Op, sub, args, aliasMap, schema)
 	}
 
-	col := q.sorts[0].Column
-	key := col.TableAlias + "." + col.ColumnName
-	val, ok := q.pagination.LastSeen[key]
-	if !ok {
-		return "", nil
+	var left string
+	if agg, ok := aggs[f.Column.ColumnName]; ok && f.Column.TableAlias == "" {
+		expr, err := q.renderAggregate(agg, aliasMap, schema)
+		if err != nil {
+			return "", err
+		}
+		left = expr
+	} else {
+		if err := q.validateCol(f.Column, aliasMap, schema); err != nil {
+			return "", fmt.Errorf("invalid column: %v", err)
+		}
+		left = q.quoteCol(f.Column)
+	}
+	if _, ok := allowedOperators[strings.ToUpper(f.Op)]; !ok {
+		return "", fmt.Errorf("invalid operator: %s", f.Op)
+	}
+
+	// A *Query value is rendered inline as a subquery, e.g. "col IN (SELECT ...)".
+	if sub, ok := f.Value.(*Query); ok {
+		return q.buildInSubquery(left, f.Op, sub, args, aliasMap, schema)
+	}
+
+	// A ColumnRef value compares against another column instead of a bound value.
+	if right, ok := f.Value.(ColumnRef); ok {
+		return q.buildColumnComparison(left, strings.ToUpper(f.Op), right, aliasMap, schema)
+	}
+
+	val, err := q.coerceFilterValue(f, strings.ToUpper(f.Op), aliasMap)
+	if err != nil {
+		return "", err
 	}
+	return q.buildComparison(left, strings.ToUpper(f.Op), val, args)
+}
 
-	op := ">"
-	if strings.ToUpper(q.sorts[0].Dir) == "DESC" {
-		op = "<"
+// buildInList renders an IN or NOT IN comparison against a list of values.
+//
+// Slices and arrays (other than []byte) expand into one placeholder per
+// element. An empty list renders a constant predicate, since "IN ()" is not
+// valid SQL.
+func (q *Query) buildInList(left, op string, val interface{}, args *[]interface{}) string {
+	rv := reflect.ValueOf(val)
+	isList := rv.IsValid() && (rv.Kind() == reflect.Slice || rv.Kind() == reflect.Array) && rv.Type().Elem().Kind() != reflect.Uint8
+	if !isList {
+		*args = append(*args, val)
+		return fmt.Sprintf("%s %s (%s)", left, op, q.dialect.Placeholder(len(*args)))
+	}
+
+	if q.caps().ArrayParams {
+		*args = append(*args, val)
+		if op == "NOT IN" {
+			return fmt.Sprintf("%s <> ALL(%s)", left, q.dialect.Placeholder(len(*args)))
+		}
+		return fmt.Sprintf("%s = ANY(%s)", left, q.dialect.Placeholder(len(*args)))
 	}
 
-	*args = append(*args, val)
-	return fmt.Sprintf("%s.%s %s %s", col.TableAlias, col.ColumnName, op, q.dialect.Placeholder(len(*args))), nil
+	if rv.Len() == 0 {
+		return q.boolLiteral(op == "NOT IN")
+	}
+	placeholders := make([]string, rv.Len())
+	for n := 0; n < rv.Len(); n++ {
+		*args = append(*args, rv.Index(n).Interface())
+		placeholders[n] = q.dialect.Placeholder(len(*args))
+	}
+	return fmt.Sprintf("%s %s (%s)", left, op, strings.Join(placeholders, ", "))
 }
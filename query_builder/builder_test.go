@@ -0,0 +1,127 @@
+package query_builder
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestJoinOperator(t *testing.T) {
+	tests := []struct {
+		op      string
+		want    string
+		wantErr string
+	}{
+		{"=", "ON `u`.`id` = `o`.`user_id`", ""},
+		{"<>", "ON `u`.`id` <> `o`.`user_id`", ""},
+		{"ilike", "ON LOWER(`u`.`id`) LIKE LOWER(`o`.`user_id`)", ""},
+		{"= 1 OR 1=1 --", "", "invalid join operator"},
+		{"IN", "", "invalid join operator"},
+		{"IS NULL", "", "invalid join operator"},
+		{"", "", "invalid join operator"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.op, func(t *testing.T) {
+			sql, _, err := New(MySQLDialect{}).From("users", "u").Join("INNER", "orders", "o", "u.id", "o.user_id", tt.op).Build()
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("Build error = %v, want %q; sql = %s", err, tt.wantErr, sql)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !strings.Contains(sql, tt.want) {
+				t.Errorf("sql = %s, want it to contain %s", sql, tt.want)
+			}
+		})
+	}
+}
+
+func TestSelectStar(t *testing.T) {
+	schema := map[string]map[string]bool{"users": {"id": true, "name": true}, "orders": {"id": true, "user_id": true}}
+	tests := []struct {
+		name string
+		q    interface {
+			Build() (string, []interface{}, error)
+		}
+		want    string
+		wantErr string
+	}{
+		{"alias star", New(PostgresDialect{}).From("users", "u").Select("u.*"), `SELECT "u".* FROM "users" "u"`, ""},
+		{"bare star", New(MySQLDialect{}).From("users", "u").Select("*"), "SELECT * FROM `users` `u`", ""},
+		{"join star", New(PostgresDialect{}).WithSchema(schema).From("users", "u").Join("INNER", "orders", "o", "u.id", "o.user_id", "=").Select("u.name", "o.*"),
+			`SELECT "u"."name", "o".* FROM "users" "u" INNER JOIN "orders" "o" ON "u"."id" = "o"."user_id"`, ""},
+		{"unknown alias", New(PostgresDialect{}).WithSchema(schema).From("users", "u").Select("x.*"), "", "invalid column: x.*"},
+		{"cte body", New(PostgresDialect{}).WithSchema(schema).With("o2", New(PostgresDialect{}).From("orders", "o").Select("o.*")).From("o2", "x").Select("x.user_id"),
+			`WITH "o2" AS (SELECT "o".* FROM "orders" "o") SELECT "x"."user_id" FROM "o2" "x"`, ""},
+		{"set part without schema", New(PostgresDialect{}).From("users", "u").Select("u.*").Union(New(PostgresDialect{}).From("users", "v").Select("v.id")), "", "explicit columns"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sql, _, err := tt.q.Build()
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("Build error = %v, want %q; sql = %s", err, tt.wantErr, sql)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if sql != tt.want {
+				t.Errorf("sql =\n%s\nwant\n%s", sql, tt.want)
+			}
+		})
+	}
+}
+
+func TestInList(t *testing.T) {
+	users := func(d Dialect, op string, val interface{}) *Query {
+		return New(d).From("users", "u").Select("u.id").Where(And(F("u.id", op, val)))
+	}
+	tests := []struct {
+		name string
+		q    *Query
+		want string
+		args []interface{}
+	}{
+		{"slice", users(PostgresDialect{}, "IN", []int{1, 2, 3}),
+			`SELECT "u"."id" FROM "users" "u" WHERE "u"."id" IN ($1, $2, $3)`, []interface{}{1, 2, 3}},
+		{"array", users(MySQLDialect{}, "not in", [2]string{"a", "b"}),
+			"SELECT `u`.`id` FROM `users` `u` WHERE `u`.`id` NOT IN (?, ?)", []interface{}{"a", "b"}},
+		{"interface slice", users(OracleDialect{}, "IN", []interface{}{1, "x"}),
+			`SELECT "u"."id" FROM "users" "u" WHERE "u"."id" IN (:1, :2)`, []interface{}{1, "x"}},
+		{"scalar", users(PostgresDialect{}, "IN", 7),
+			`SELECT "u"."id" FROM "users" "u" WHERE "u"."id" IN ($1)`, []interface{}{7}},
+		{"bytes are one value", users(PostgresDialect{}, "IN", []byte("ab")),
+			`SELECT "u"."id" FROM "users" "u" WHERE "u"."id" IN ($1)`, []interface{}{[]byte("ab")}},
+		{"empty in", users(PostgresDialect{}, "IN", []int{}),
+			`SELECT "u"."id" FROM "users" "u" WHERE FALSE`, nil},
+		{"empty not in", users(PostgresDialect{}, "NOT IN", []string(nil)),
+			`SELECT "u"."id" FROM "users" "u" WHERE TRUE`, nil},
+		{"empty without boolean literals", users(OracleDialect{}, "IN", []int{}),
+			`SELECT "u"."id" FROM "users" "u" WHERE 1 = 0`, nil},
+		{"any array", users(PostgresDialect{AnyArray: true}, "IN", []int64{1, 2}),
+			`SELECT "u"."id" FROM "users" "u" WHERE "u"."id" = ANY($1)`, []interface{}{[]int64{1, 2}}},
+		{"all array", users(PostgresDialect{AnyArray: true}, "NOT IN", []string{}),
+			`SELECT "u"."id" FROM "users" "u" WHERE "u"."id" <> ALL($1)`, []interface{}{[]string{}}},
+		{"any array scalar", users(PostgresDialect{AnyArray: true}, "IN", 7),
+			`SELECT "u"."id" FROM "users" "u" WHERE "u"."id" IN ($1)`, []interface{}{7}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sql, args, err := tt.q.Build()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if sql != tt.want {
+				t.Errorf("sql =\n%s\nwant\n%s", sql, tt.want)
+			}
+			if !reflect.DeepEqual(args, tt.args) {
+				t.Errorf("args = %#v, want %#v", args, tt.args)
+			}
+		})
+	}
+}
@@ -0,0 +1,151 @@
+package query_builder
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Capabilities describes the SQL features a dialect supports beyond
+// placeholders and quoting. Build consults it to pick the right syntax and
+// returns an error for features the dialect lacks.
+//
+// The zero value describes a conservative ANSI dialect: LIMIT/OFFSET
+// pagination, no RETURNING, no upsert, no ILIKE, no row values, no NULLS
+// FIRST/LAST, no TRUE/FALSE literals and no parameter or row limit. A dialect can
+// therefore opt in to features one field at a time.
+type Capabilities struct {
+	Pagination       PaginationStyle // Syntax used to limit and skip rows
+	Returning        ReturningStyle  // How DML statements return affected rows
+	Upsert           UpsertStyle     // How an insert-or-update statement is rendered
+	ILike            bool            // If true, the dialect has a native case-insensitive ILIKE
+	RowValues        bool            // If true, row values can be compared, e.g. (a, b) > (x, y)
+	NullsOrdering    bool            // If true, ORDER BY accepts NULLS FIRST and NULLS LAST
+	BooleanLiterals  bool            // If true, TRUE and FALSE can be used as predicates
+	ArrayParams      bool            // If true, IN over a slice binds one array parameter as "= ANY($1)"
+	MaxParams        int             // Maximum bound parameters per statement; 0 means no limit
+	MaxInsertRows    int             // Maximum rows in one INSERT ... VALUES; 0 means no limit
+	UnsupportedJoins []string        // Join types the dialect rejects, e.g. "RIGHT" or "FULL"
+	ExceptKeyword    string          // Spelling of EXCEPT, e.g. "MINUS"; empty means "EXCEPT"
+	ImplicitRecurse  bool            // If true, recursive CTEs are written without the RECURSIVE keyword
+	InsertAll        bool            // If true, multi-row inserts use INSERT ALL ... SELECT 1 FROM DUAL
+	DMLAlias         DMLAliasStyle   // How UPDATE and DELETE declare a table alias
+}
+
+// DMLAliasStyle describes how UPDATE and DELETE statements alias their target table.
+type DMLAliasStyle int
+
+const (
+	// DMLAliasPlain writes the alias after the table: UPDATE t a, DELETE FROM t a.
+	DMLAliasPlain DMLAliasStyle = iota
+	// DMLAliasAs writes the alias with AS: UPDATE t AS a, DELETE FROM t AS a (SQLite).
+	DMLAliasAs
+	// DMLAliasFrom names the alias as the target and declares it in a FROM
+	// clause: UPDATE a SET ... FROM t a, DELETE a FROM t a (SQL Server).
+	DMLAliasFrom
+)
+
+// Capable is an optional Dialect extension that reports the dialect's capabilities.
+//
+// Dialects that do not implement it get the zero Capabilities.
+type Capable interface {
+	Capabilities() Capabilities
+}
+
+// CapabilitiesOf returns the capabilities of d.
+func CapabilitiesOf(d Dialect) Capabilities {
+	if c, ok := d.(Capable); ok {
+		return c.Capabilities()
+	}
+	return Capabilities{}
+}
+
+// Capabilities reports the features of PostgreSQL.
+func (p PostgresDialect) Capabilities() Capabilities {
+	return Capabilities{
+		Pagination:      PaginationLimitOffset,
+		Returning:       ReturningClause,
+		Upsert:          UpsertOnConflict,
+		ILike:           true,
+		RowValues:       true,
+		NullsOrdering:   true,
+		BooleanLiterals: true,
+		ArrayParams:     p.AnyArray,
+		MaxParams:       65535,
+	}
+}
+
+// Capabilities reports the features of MySQL.
+func (m MySQLDialect) Capabilities() Capabilities {
+	return Capabilities{
+		Pagination:       PaginationLimitRequired,
+		Upsert:           UpsertOnDuplicateKey,
+		RowValues:        true,
+		BooleanLiterals:  true,
+		MaxParams:        65535,
+		UnsupportedJoins: []string{"FULL"},
+	}
+}
+
+// Capabilities reports the features of Oracle 12c and later.
+func (o OracleDialect) Capabilities() Capabilities {
+	return Capabilities{
+		Pagination:      PaginationOffsetFetch,
+		Returning:       ReturningInto,
+		Upsert:          UpsertMerge,
+		NullsOrdering:   true,
+		ExceptKeyword:   "MINUS",
+		ImplicitRecurse: true,
+		InsertAll:       true,
+	}
+}
+
+// caps returns the capabilities of the query's dialect.
+func (q *Query) caps() Capabilities {
+	return CapabilitiesOf(q.dialect)
+}
+
+// supportsJoin reports whether d accepts joinType.
+func supportsJoin(d Dialect, joinType string) bool {
+	for _, j := range CapabilitiesOf(d).UnsupportedJoins {
+		if strings.EqualFold(j, joinType) {
+			return false
+		}
+	}
+	return true
+}
+
+// settingsChecker is implemented by dialects whose settings can be invalid,
+// such as SQLiteDialect with a malformed Version.
+type settingsChecker interface {
+	checkSettings() error
+}
+
+// checkDialect returns the error in d's settings, if any.
+func checkDialect(d Dialect) error {
+	if c, ok := d.(settingsChecker); ok {
+		return c.checkSettings()
+	}
+	return nil
+}
+
+// checkParamLimit returns an error when args exceed the dialect's parameter limit.
+func checkParamLimit(d Dialect, args []interface{}) error {
+	limit := CapabilitiesOf(d).MaxParams
+	if limit <= 0 || len(args) <= limit {
+		return nil
+	}
+	return fmt.Errorf("statement has %d parameters, dialect %T allows at most %d", len(args), d, limit)
+}
+
+// boolLiteral renders a constant predicate, using TRUE and FALSE when the dialect has them.
+func (q *Query) boolLiteral(b bool) string {
+	switch {
+	case q.caps().BooleanLiterals && b:
+		return "TRUE"
+	case q.caps().BooleanLiterals:
+		return "FALSE"
+	case b:
+		return "1 = 1"
+	}
+	return "1 = 0"
+}
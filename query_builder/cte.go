@@ -0,0 +1,218 @@
+package query_builder
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// commonTable is a single WITH entry.
+type commonTable struct {
+	Name      string // Name used in From and Join
+	Query     *Query // The CTE body, or the anchor member when recursive
+	Recursive *Query // The recursive member; nil for a plain CTE
+}
+
+// With prefixes the query with a common table expression.
+//
+// name can then be used in From and Join like a table. When schema validation
+// is enabled, the columns projected by sub are registered as a virtual table.
+func (q *Query) With(name string, sub *Query) *Query {
+	q.ctes = append(q.ctes, commonTable{Name: name, Query: sub})
+	return q
+}
+
+// WithRecursive prefixes the query with a recursive common table expression.
+//
+// The CTE is rendered as "anchor UNION ALL recursive". The recursive member
+// may reference name itself, and both members must select the same number of
+// explicit columns.
+func (q *Query) WithRecursive(name string, anchor, recursive *Query) *Query {
+	q.ctes = append(q.ctes, commonTable{Name: name, Query: anchor, Recursive: recursive})
+	if recursive == nil {
+		q.errors = append(q.errors, fmt.Errorf("recursive member required for CTE: %s", name))
+	}
+	return q
+}
+
+// buildCTEs renders the WITH clause and returns schema extended with one
+// virtual table per CTE. A nil schema stays nil.
+func (q *Query) buildCTEs(sb *strings.Builder, args *[]interface{}, schema map[string]map[string]bool) (map[string]map[string]bool, error) {
+	if len(q.ctes) == 0 {
+		return schema, nil
+	}
+
+	recursive := false
+	for _, c := range q.ctes {
+		if c.Recursive != nil {
+			recursive = true
+		}
+	}
+	sb.WriteString("WITH ")
+	// Some dialects infer recursion from the self reference and reject the keyword.
+	if recursive && !q.caps().ImplicitRecurse {
+		sb.WriteString("RECURSIVE ")
+	}
+
+	// virtual holds the columns of every CTE rendered so far.
+	virtual := make(map[string]map[string]bool)
+	var parts []string
+	for _, c := range q.ctes {
+		if c.Name == "" {
+			return nil, errors.New("CTE name required")
+		}
+		if _, exists := virtual[c.Name]; exists {
+			return nil, fmt.Errorf("duplicate CTE name: %s", c.Name)
+		}
+		if c.Query == nil {
+			return nil, fmt.Errorf("CTE query required: %s", c.Name)
+		}
+
+		cols, err := c.Query.outputColumns(nestedSchema(c.Query, schema, virtual))
+		if err != nil {
+			return nil, fmt.Errorf("invalid CTE %s: %v", c.Name, err)
+		}
+
+		if c.Recursive == nil {
+			body, err := q.renderSubquery(namedCount(c.Query), args, nil, schema, virtual)
+			if err != nil {
+				return nil, err
+			}
+			virtual[c.Name] = columnSet(cols)
+			parts = append(parts, fmt.Sprintf("%s AS (%s)", q.quoteIdent(c.Name), body))
+			continue
+		}
+
+		// The recursive member sees the CTE itself, so register it first.
+		if len(c.Query.projections) == 0 || c.Query.selectsStar() || len(c.Recursive.projections) != len(c.Query.projections) {
+			return nil, fmt.Errorf("recursive CTE %s members must select the same explicit columns", c.Name)
+		}
+		virtual[c.Name] = columnSet(cols)
+		anchor, err := q.renderSubquery(c.Query, args, nil, schema, virtual)
+		if err != nil {
+			return nil, err
+		}
+		rec, err := q.renderSubquery(c.Recursive, args, nil, schema, virtual)
+		if err != nil {
+			return nil, err
+		}
+		parts = append(parts, fmt.Sprintf("%s (%s) AS (%s UNION ALL %s)",
+			q.quoteIdent(c.Name), strings.Join(q.quoteIdents(cols), ", "), anchor, rec))
+	}
+	sb.WriteString(strings.Join(parts, ", ") + " ")
+	return mergeSchemas(schema, virtual), nil
+}
+
+// outputColumns returns the names of the columns the query projects.
+//
+// A query without explicit projections selects base.*; the columns of a *
+// projection are taken from schema and returned in sorted order.
+func (q *Query) outputColumns(schema map[string]map[string]bool) ([]string, error) {
+	if q.isCount {
+		return []string{"count"}, nil
+	}
+	if len(q.projections) == 0 {
+		return tableColumns(schema[q.baseTable]), nil
+	}
+	var cols []string
+	seen := make(map[string]bool)
+	for _, p := range q.projections {
+		names := []string{p.Column.ColumnName}
+		if p.Aggregate != nil {
+			names = []string{p.Aggregate.Alias}
+		} else if p.Column.ColumnName == "*" {
+			table, err := q.starTable(p.Column)
+			if err != nil {
+				return nil, err
+			}
+			names = tableColumns(schema[table])
+		}
+		for _, name := range names {
+			if seen[name] {
+				return nil, fmt.Errorf("duplicate output column: %s", name)
+			}
+			seen[name] = true
+			cols = append(cols, name)
+		}
+	}
+	return cols, nil
+}
+
+// starTable returns the table whose columns an "alias.*" projection selects.
+//
+// A bare * is only accepted without joins, where it means the base table.
+func (q *Query) starTable(ref ColumnRef) (string, error) {
+	if ref.TableAlias == "" {
+		if len(q.joins) > 0 {
+			return "", errors.New("select * with joins has ambiguous output columns; use alias.*")
+		}
+		return q.baseTable, nil
+	}
+	aliasMap, err := q.registerAliases(nil)
+	if err != nil {
+		return "", err
+	}
+	table, ok := aliasMap[ref.TableAlias]
+	if !ok {
+		return "", fmt.Errorf("invalid column: %s.*", ref.TableAlias)
+	}
+	return table, nil
+}
+
+// selectsStar reports whether any projection is "*" or "alias.*".
+func (q *Query) selectsStar() bool {
+	for _, p := range q.projections {
+		if p.Aggregate == nil && p.Column.ColumnName == "*" {
+			return true
+		}
+	}
+	return false
+}
+
+// tableColumns returns the column names of an allow-list table in sorted order.
+func tableColumns(table map[string]bool) []string {
+	var cols []string
+	for c := range table {
+		cols = append(cols, c)
+	}
+	sort.Strings(cols)
+	return cols
+}
+
+// namedCount returns sub, or for a count query a copy that names its COUNT(*)
+// "count", matching the output column outputColumns reports.
+func namedCount(sub *Query) *Query {
+	if sub == nil || !sub.isCount {
+		return sub
+	}
+	named := *sub
+	named.countAlias = true
+	return &named
+}
+
+// columnSet converts a column list into the allow-list form used by schemas.
+func columnSet(cols []string) map[string]bool {
+	set := make(map[string]bool, len(cols))
+	for _, c := range cols {
+		set[c] = true
+	}
+	return set
+}
+
+// mergeSchemas returns a copy of schema with the tables of extra added.
+//
+// A nil schema means validation is off, so it is returned unchanged.
+func mergeSchemas(schema, extra map[string]map[string]bool) map[string]map[string]bool {
+	if schema == nil {
+		return nil
+	}
+	merged := make(map[string]map[string]bool, len(schema)+len(extra))
+	for t, cols := range schema {
+		merged[t] = cols
+	}
+	for t, cols := range extra {
+		merged[t] = cols
+	}
+	return merged
+}
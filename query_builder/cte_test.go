@@ -0,0 +1,63 @@
+package query_builder
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCTE(t *testing.T) {
+	schema := map[string]map[string]bool{
+		"users":     {"id": true, "name": true},
+		"employees": {"id": true, "manager_id": true},
+	}
+	active := func(d Dialect) *Query { return New(d).From("users", "u").Eq("u.name", "x").Count() }
+	chain := func(d Dialect) *Query {
+		anchor := New(d).From("employees", "e").Select("e.id", "e.manager_id").Where(And(F("e.id", "=", 7)))
+		rec := New(d).From("employees", "e").Select("e.id", "e.manager_id").
+			Join("INNER", "chain", "c", "e.id", "c.manager_id", "=")
+		return New(d).WithSchema(schema).WithRecursive("chain", anchor, rec).From("chain", "c").Select("c.id")
+	}
+	tests := []struct {
+		name    string
+		q       *Query
+		want    string
+		wantErr string
+	}{
+		{"count body sqlserver", New(SQLServerDialect{}).WithSchema(schema).With("n", active(SQLServerDialect{})).From("n", "n").Select("n.count"),
+			`WITH [n] AS (SELECT COUNT(*) AS [count] FROM [users] [u] WHERE [u].[name] = @p1) SELECT [n].[count] FROM [n] [n]`, ""},
+		{"count body mysql", New(MySQLDialect{}).With("n", active(MySQLDialect{})).From("n", "n").Select("n.count"),
+			"WITH `n` AS (SELECT COUNT(*) AS `count` FROM `users` `u` WHERE `u`.`name` = ?) SELECT `n`.`count` FROM `n` `n`", ""},
+		{"recursive postgres", chain(PostgresDialect{}),
+			`WITH RECURSIVE "chain" ("id", "manager_id") AS (SELECT "e"."id", "e"."manager_id" FROM "employees" "e" WHERE "e"."id" = $1 UNION ALL SELECT "e"."id", "e"."manager_id" FROM "employees" "e" INNER JOIN "chain" "c" ON "e"."id" = "c"."manager_id") SELECT "c"."id" FROM "chain" "c"`, ""},
+		{"recursive sqlserver", chain(SQLServerDialect{}),
+			`WITH [chain] ([id], [manager_id]) AS (SELECT [e].[id], [e].[manager_id] FROM [employees] [e] WHERE [e].[id] = @p1 UNION ALL SELECT [e].[id], [e].[manager_id] FROM [employees] [e] INNER JOIN [chain] [c] ON [e].[id] = [c].[manager_id]) SELECT [c].[id] FROM [chain] [c]`, ""},
+		{"recursive column mismatch", New(PostgresDialect{}).WithRecursive("chain",
+			New(PostgresDialect{}).From("employees", "e").Select("e.id"),
+			New(PostgresDialect{}).From("employees", "e").Select("e.id", "e.manager_id")).From("chain", "c"),
+			"", "members must select the same explicit columns"},
+		{"unknown cte column", New(PostgresDialect{}).WithSchema(schema).With("n", active(PostgresDialect{})).From("n", "n").Select("n.total"),
+			"", "invalid column: n.total"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sql, _, err := tt.q.Build()
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("Build error = %v, want %q; sql = %s", err, tt.wantErr, sql)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if sql != tt.want {
+				t.Errorf("sql =\n%s\nwant\n%s", sql, tt.want)
+			}
+		})
+	}
+
+	// A count query keeps its bare COUNT(*) outside a CTE.
+	if sql, _, _ := active(PostgresDialect{}).Build(); sql != `SELECT COUNT(*) FROM "users" "u" WHERE "u"."name" = $1` {
+		t.Errorf("top-level count = %s", sql)
+	}
+}
@@ -0,0 +1,241 @@
+package query_builder
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Cursor errors returned by Build and CursorCodec.Decode. Use errors.Is to test for them.
+var (
+	// ErrInvalidCursor means the token is not a well-formed cursor.
+	ErrInvalidCursor = errors.New("invalid cursor")
+	// ErrCursorSignature means the token's signature does not match its content.
+	ErrCursorSignature = errors.New("cursor signature mismatch")
+	// ErrCursorSortMismatch means the token was issued for a different sort order.
+	ErrCursorSortMismatch = errors.New("cursor sort order mismatch")
+)
+
+// CursorCodec encodes and verifies opaque keyset pagination cursors.
+//
+// A token is the base64 encoding of the last-seen sort values, each tagged
+// with its type, followed by an HMAC-SHA256 signature computed with the
+// configured key. Clients can pass tokens back but cannot read or forge them.
+type CursorCodec struct {
+	key []byte // HMAC signing key
+}
+
+// NewCursorCodec returns a CursorCodec that signs tokens with key.
+//
+// key should be at least 32 random bytes and kept secret.
+func NewCursorCodec(key []byte) *CursorCodec {
+	return &CursorCodec{key: key}
+}
+
+// cursorPayload is the signed content of a cursor token.
+type cursorPayload struct {
+	Sorts  string        `json:"s"` // Fingerprint of the query's sort order
+	Values []cursorValue `json:"v"` // Last-seen values, one per sort column
+}
+
+// cursorValue is a type-tagged value, so decoding restores the original Go type.
+type cursorValue struct {
+	Type  string `json:"t"`           // i=int64, u=uint64, f=float64, s=string, b=bool, t=time, x=bytes, n=null
+	Value string `json:"v,omitempty"` // The value in its canonical string form
+}
+
+// Encode returns a cursor token for the last row of a page of q's results.
+//
+// lastRow holds the row's values keyed by "alias.column", and must contain
+// every sort column of q.
+func (c *CursorCodec) Encode(q *Query, lastRow map[string]interface{}) (string, error) {
+	if len(c.key) == 0 {
+		return "", errors.New("cursor key required")
+	}
+	if len(q.sorts) == 0 {
+		return "", errors.New("cursor requires ORDER BY")
+	}
+	p := cursorPayload{Sorts: sortFingerprint(q)}
+	for _, s := range q.sorts {
+		key := s.Column.TableAlias + "." + s.Column.ColumnName
+		val, ok := lastRow[key]
+		if !ok {
+			return "", fmt.Errorf("cursor value missing for sort column: %s", key)
+		}
+		cv, err := encodeCursorValue(val)
+		if err != nil {
+			return "", fmt.Errorf("cursor value for %s: %v", key, err)
+		}
+		p.Values = append(p.Values, cv)
+	}
+	body, err := json.Marshal(p)
+	if err != nil {
+		return "", err
+	}
+	enc := base64.RawURLEncoding
+	return enc.EncodeToString(body) + "." + enc.EncodeToString(c.sign(body)), nil
+}
+
+// Decode verifies token against q's sort order and returns the last-seen
+// values keyed by "alias.column", ready for KeysetPagination.
+func (c *CursorCodec) Decode(q *Query, token string) (map[string]interface{}, error) {
+	if len(c.key) == 0 {
+		return nil, errors.New("cursor key required")
+	}
+	enc := base64.RawURLEncoding
+	dot := strings.IndexByte(token, '.')
+	if dot < 0 {
+		return nil, ErrInvalidCursor
+	}
+	body, err := enc.DecodeString(token[:dot])
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	sig, err := enc.DecodeString(token[dot+1:])
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	if !hmac.Equal(sig, c.sign(body)) {
+		return nil, ErrCursorSignature
+	}
+
+	var p cursorPayload
+	if err := json.Unmarshal(body, &p); err != nil {
+		return nil, ErrInvalidCursor
+	}
+	if p.Sorts != sortFingerprint(q) || len(p.Values) != len(q.sorts) {
+		return nil, ErrCursorSortMismatch
+	}
+	lastSeen := make(map[string]interface{}, len(p.Values))
+	for i, s := range q.sorts {
+		val, err := decodeCursorValue(p.Values[i])
+		if err != nil {
+			return nil, err
+		}
+		lastSeen[s.Column.TableAlias+"."+s.Column.ColumnName] = val
+	}
+	return lastSeen, nil
+}
+
+// sign returns the HMAC-SHA256 of body.
+func (c *CursorCodec) sign(body []byte) []byte {
+	mac := hmac.New(sha256.New, c.key)
+	mac.Write(body)
+	return mac.Sum(nil)
+}
+
+// WithCursorCodec sets the codec used by AfterCursor and BeforeCursor.
+func (q *Query) WithCursorCodec(codec *CursorCodec) *Query {
+	q.cursorCodec = codec
+	return q
+}
+
+// AfterCursor configures keyset pagination from an opaque cursor token.
+//
+// The token is verified and decoded by Build, using the codec set with
+// WithCursorCodec and the query's final sort order. An empty token requests
+// the first page.
+func (q *Query) AfterCursor(token string) *Query {
+	q.pagination = Pagination{
+		Type:   "keyset",
+		Cursor: token,
+	}
+	return q
+}
+
+// BeforeCursor configures keyset pagination towards the page before an
+// opaque cursor token, as KeysetBefore does for raw values.
+//
+// Encode the token from the first row of the current page. Rows come back in
+// reverse order; see IsBackward.
+func (q *Query) BeforeCursor(token string) *Query {
+	q.pagination = Pagination{
+		Type:     "keyset",
+		Cursor:   token,
+		Backward: true,
+	}
+	return q
+}
+
+// lastSeen returns the keyset values, decoding the cursor token if one is set.
+func (q *Query) lastSeen() (map[string]interface{}, error) {
+	if q.pagination.Cursor == "" {
+		return q.pagination.LastSeen, nil
+	}
+	if q.cursorCodec == nil {
+		return nil, errors.New("cursor codec required")
+	}
+	return q.cursorCodec.Decode(q, q.pagination.Cursor)
+}
+
+// sortFingerprint identifies the table and sort order a cursor belongs to.
+func sortFingerprint(q *Query) string {
+	parts := []string{q.baseTable}
+	for _, s := range q.sorts {
+		parts = append(parts, fmt.Sprintf("%s.%s %s", s.Column.TableAlias, s.Column.ColumnName, strings.ToUpper(s.Dir)))
+	}
+	return strings.Join(parts, ",")
+}
+
+// encodeCursorValue tags val with its type and converts it to a string.
+func encodeCursorValue(val interface{}) (cursorValue, error) {
+	switch v := val.(type) {
+	case nil:
+		return cursorValue{Type: "n"}, nil
+	case string:
+		return cursorValue{Type: "s", Value: v}, nil
+	case bool:
+		return cursorValue{Type: "b", Value: strconv.FormatBool(v)}, nil
+	case time.Time:
+		return cursorValue{Type: "t", Value: v.Format(time.RFC3339Nano)}, nil
+	case []byte:
+		return cursorValue{Type: "x", Value: base64.StdEncoding.EncodeToString(v)}, nil
+	}
+	rv := reflect.ValueOf(val)
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return cursorValue{Type: "i", Value: strconv.FormatInt(rv.Int(), 10)}, nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return cursorValue{Type: "u", Value: strconv.FormatUint(rv.Uint(), 10)}, nil
+	case reflect.Float32, reflect.Float64:
+		return cursorValue{Type: "f", Value: strconv.FormatFloat(rv.Float(), 'g', -1, 64)}, nil
+	}
+	return cursorValue{}, fmt.Errorf("unsupported type %T", val)
+}
+
+// decodeCursorValue restores a value encoded by encodeCursorValue.
+func decodeCursorValue(cv cursorValue) (interface{}, error) {
+	var val interface{}
+	var err error
+	switch cv.Type {
+	case "n":
+		return nil, nil
+	case "s":
+		return cv.Value, nil
+	case "b":
+		val, err = strconv.ParseBool(cv.Value)
+	case "t":
+		val, err = time.Parse(time.RFC3339Nano, cv.Value)
+	case "x":
+		val, err = base64.StdEncoding.DecodeString(cv.Value)
+	case "i":
+		val, err = strconv.ParseInt(cv.Value, 10, 64)
+	case "u":
+		val, err = strconv.ParseUint(cv.Value, 10, 64)
+	case "f":
+		val, err = strconv.ParseFloat(cv.Value, 64)
+	default:
+		return nil, ErrInvalidCursor
+	}
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	return val, nil
+}
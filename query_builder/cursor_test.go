@@ -0,0 +1,134 @@
+package query_builder
+
+import (
+	"encoding/base64"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+var testCursorKey = []byte("0123456789abcdef0123456789abcdef")
+
+func cursorQuery() *Query {
+	return New(PostgresDialect{}).From("users", "u").OrderBy("u.created_at", "DESC").OrderBy("u.id", "ASC").Limit(20)
+}
+
+func TestCursorRoundTrip(t *testing.T) {
+	codec := NewCursorCodec(testCursorKey)
+	created := time.Date(2026, 10, 16, 9, 30, 0, 123456789, time.UTC)
+	tests := []struct {
+		name string
+		in   interface{}
+		want interface{}
+	}{
+		{"int", 42, int64(42)},
+		{"int64", int64(-7), int64(-7)},
+		{"uint", uint32(7), uint64(7)},
+		{"float", 1.5, 1.5},
+		{"string", "a.b", "a.b"},
+		{"bool", true, true},
+		{"time", created, created},
+		{"bytes", []byte{0, 1, 255}, []byte{0, 1, 255}},
+		{"nil", nil, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q := cursorQuery()
+			token, err := codec.Encode(q, map[string]interface{}{"u.created_at": tt.in, "u.id": 9})
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := codec.Decode(q, token)
+			if err != nil {
+				t.Fatal(err)
+			}
+			want := map[string]interface{}{"u.created_at": tt.want, "u.id": int64(9)}
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("Decode = %#v, want %#v", got, want)
+			}
+		})
+	}
+}
+
+func TestCursorRejectsTampering(t *testing.T) {
+	codec := NewCursorCodec(testCursorKey)
+	q := cursorQuery()
+	token, err := codec.Encode(q, map[string]interface{}{"u.created_at": "2026-10-16", "u.id": 9})
+	if err != nil {
+		t.Fatal(err)
+	}
+	dot := strings.IndexByte(token, '.')
+	body, _ := base64.RawURLEncoding.DecodeString(token[:dot])
+	forged := base64.RawURLEncoding.EncodeToString([]byte(strings.Replace(string(body), `"9"`, `"10"`, 1))) + token[dot:]
+
+	tests := []struct {
+		name  string
+		codec *CursorCodec
+		q     *Query
+		token string
+		want  error
+	}{
+		{"edited values", codec, q, forged, ErrCursorSignature},
+		{"other key", NewCursorCodec([]byte("another key of thirty-two bytes!")), q, token, ErrCursorSignature},
+		{"other sort", codec, New(PostgresDialect{}).From("users", "u").OrderBy("u.created_at", "ASC").OrderBy("u.id", "ASC"), token, ErrCursorSortMismatch},
+		{"other table", codec, New(PostgresDialect{}).From("orders", "u").OrderBy("u.created_at", "DESC").OrderBy("u.id", "ASC"), token, ErrCursorSortMismatch},
+		{"no separator", codec, q, strings.Replace(token, ".", "", 1), ErrInvalidCursor},
+		{"bad base64", codec, q, "!!!." + token[dot+1:], ErrInvalidCursor},
+		{"empty", codec, q, "", ErrInvalidCursor},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := tt.codec.Decode(tt.q, tt.token); !errors.Is(err, tt.want) {
+				t.Errorf("Decode error = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestCursorEncodeErrors(t *testing.T) {
+	codec := NewCursorCodec(testCursorKey)
+	if _, err := codec.Encode(cursorQuery(), map[string]interface{}{"u.id": 1}); err == nil {
+		t.Error("expected an error for a missing sort value")
+	}
+	if _, err := codec.Encode(cursorQuery(), map[string]interface{}{"u.created_at": struct{}{}, "u.id": 1}); err == nil {
+		t.Error("expected an error for an unsupported value type")
+	}
+	if _, err := codec.Encode(New(PostgresDialect{}).From("users", "u"), nil); err == nil {
+		t.Error("expected an error for a query without ORDER BY")
+	}
+	if _, err := NewCursorCodec(nil).Encode(cursorQuery(), map[string]interface{}{"u.created_at": 1, "u.id": 1}); err == nil {
+		t.Error("expected an error for an empty key")
+	}
+}
+
+func TestAfterCursorBuild(t *testing.T) {
+	codec := NewCursorCodec(testCursorKey)
+	token, err := codec.Encode(cursorQuery(), map[string]interface{}{"u.created_at": "2026-10-16", "u.id": 9})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sql, args, err := cursorQuery().WithCursorCodec(codec).AfterCursor(token).Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `SELECT "u".* FROM "users" "u" WHERE ("u"."created_at" < $1 OR ("u"."created_at" = $2 AND "u"."id" > $3)) ORDER BY "u"."created_at" DESC, "u"."id" ASC LIMIT $4`
+	if sql != want {
+		t.Errorf("sql =\n%s\nwant\n%s", sql, want)
+	}
+	if wantArgs := []interface{}{"2026-10-16", "2026-10-16", int64(9), 20}; !reflect.DeepEqual(args, wantArgs) {
+		t.Errorf("args = %#v, want %#v", args, wantArgs)
+	}
+
+	if _, _, err := cursorQuery().AfterCursor(token).Build(); err == nil {
+		t.Error("expected an error without a cursor codec")
+	}
+	if _, _, err := cursorQuery().WithCursorCodec(codec).AfterCursor(token + "x").Build(); !errors.Is(err, ErrCursorSignature) && !errors.Is(err, ErrInvalidCursor) {
+		t.Errorf("Build error = %v, want a cursor error", err)
+	}
+	if _, _, err := cursorQuery().WithCursorCodec(codec).AfterCursor("").Build(); err != nil {
+		t.Errorf("empty token should request the first page: %v", err)
+	}
+}
@@ -0,0 +1,177 @@
+package query_builder
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ReturningStyle describes how a dialect reports rows affected by a DML statement.
+type ReturningStyle int
+
+const (
+	// ReturningNone means the dialect has no RETURNING clause.
+	ReturningNone ReturningStyle = iota
+	// ReturningClause renders RETURNING col, ... and yields a result set (Postgres, SQLite).
+	ReturningClause
+	// ReturningInto renders RETURNING col, ... INTO :n, ... with out binds (Oracle).
+	ReturningInto
+)
+
+// DeleteQuery builds a SQL DELETE statement.
+//
+// A DeleteQuery is created with Query.Delete and shares the dialect, schema
+// and quoting settings of the Query it was created from.
+type DeleteQuery struct {
+	parent    *Query        // Query providing dialect, schema and quoting settings
+	table     string        // Target table
+	alias     string        // Alias for the target table
+	where     *FilterGroup  // Root filter group (WHERE clause)
+	fullTable bool          // If true, a DELETE without WHERE is allowed
+	returning []ColumnRef   // Columns for the RETURNING clause
+	into      []interface{} // Out-bind destinations for RETURNING ... INTO
+}
+
+// Delete returns a DeleteQuery for table using alias in the WHERE clause.
+//
+// If alias is empty, the table name is used as the alias.
+func (q *Query) Delete(table string, alias string) *DeleteQuery {
+	return &DeleteQuery{parent: q, table: table, alias: alias}
+}
+
+// Where sets the root WHERE filter group.
+//
+// Use And and Or to compose nested conditions, exactly as with Query.Where.
+func (d *DeleteQuery) Where(group *FilterGroup) *DeleteQuery {
+	d.where = group
+	return d
+}
+
+// AllowFullTable permits rendering a DELETE that has no WHERE clause.
+//
+// Without it, Build refuses to delete every row of the table.
+func (d *DeleteQuery) AllowFullTable() *DeleteQuery {
+	d.fullTable = true
+	return d
+}
+
+// Returning appends columns to return from the deleted rows.
+//
+// Build returns an error if the dialect has no RETURNING support.
+func (d *DeleteQuery) Returning(columns ...string) *DeleteQuery {
+	for _, c := range columns {
+		d.returning = append(d.returning, Col(c))
+	}
+	return d
+}
+
+// Into sets the out-bind destinations used by RETURNING ... INTO.
+//
+// Only dialects with ReturningInto style need it; pass one destination per
+// Returning column, typically sql.Out values.
+func (d *DeleteQuery) Into(dest ...interface{}) *DeleteQuery {
+	d.into = append(d.into, dest...)
+	return d
+}
+
+// Build renders the DELETE statement and bound arguments.
+func (d *DeleteQuery) Build() (string, []interface{}, error) {
+	q := d.parent
+	if len(q.errors) > 0 {
+		return "", nil, q.errors[0]
+	}
+	if err := checkDialect(q.dialect); err != nil {
+		return "", nil, err
+	}
+	if d.table == "" {
+		return "", nil, errors.New("delete table required")
+	}
+	if q.allowedSchema != nil {
+		if _, ok := q.allowedSchema[d.table]; !ok {
+			return "", nil, fmt.Errorf("invalid delete table: %s", d.table)
+		}
+	}
+
+	alias := d.getAlias()
+	aliasMap := map[string]string{alias: d.table}
+
+	var sb strings.Builder
+	var args []interface{}
+
+	if d.alias != "" && q.caps().DMLAlias == DMLAliasFrom {
+		sb.WriteString("DELETE " + q.quoteIdent(d.alias) + " FROM " + q.dmlTable(d.table, d.alias))
+	} else {
+		sb.WriteString("DELETE FROM " + q.dmlTable(d.table, d.alias))
+	}
+
+	whereClause := ""
+	if d.where != nil {
+		clause, err := q.buildFilterGroup(*d.where, &args, aliasMap, 0, q.allowedSchema, nil)
+		if err != nil {
+			return "", nil, err
+		}
+		whereClause = clause
+	}
+	if whereClause != "" {
+		sb.WriteString(" WHERE " + whereClause)
+	} else if !d.fullTable {
+		return "", nil, errors.New("delete without WHERE clause requires AllowFullTable")
+	}
+
+	if err := q.buildReturning(&sb, &args, d.returning, d.into, alias, aliasMap); err != nil {
+		return "", nil, err
+	}
+	if err := checkParamLimit(q.dialect, args); err != nil {
+		return "", nil, err
+	}
+	return sb.String(), args, nil
+}
+
+// getAlias returns the explicit alias or the table name if no alias exists.
+func (d *DeleteQuery) getAlias() string {
+	if d.alias != "" {
+		return d.alias
+	}
+	return d.table
+}
+
+// buildReturning appends a RETURNING clause in the dialect's style.
+//
+// Columns are rendered unqualified because not every dialect accepts the
+// table alias inside RETURNING.
+func (q *Query) buildReturning(sb *strings.Builder, args *[]interface{}, cols []ColumnRef, into []interface{}, alias string, aliasMap map[string]string) error {
+	if len(cols) == 0 {
+		return nil
+	}
+	var parts []string
+	for _, c := range cols {
+		if c.TableAlias == "" {
+			c.TableAlias = alias
+		}
+		if c.TableAlias != alias {
+			return fmt.Errorf("invalid returning column: %s.%s", c.TableAlias, c.ColumnName)
+		}
+		if err := q.validateCol(c, aliasMap, q.allowedSchema); err != nil {
+			return fmt.Errorf("invalid returning column: %v", err)
+		}
+		parts = append(parts, q.quoteIdent(c.ColumnName))
+	}
+
+	switch q.caps().Returning {
+	case ReturningClause:
+		sb.WriteString(" RETURNING " + strings.Join(parts, ", "))
+	case ReturningInto:
+		if len(into) != len(cols) {
+			return fmt.Errorf("RETURNING ... INTO requires %d destinations, got %d", len(cols), len(into))
+		}
+		var binds []string
+		for _, dest := range into {
+			*args = append(*args, dest)
+			binds = append(binds, q.dialect.Placeholder(len(*args)))
+		}
+		sb.WriteString(fmt.Sprintf(" RETURNING %s INTO %s", strings.Join(parts, ", "), strings.Join(binds, ", ")))
+	default:
+		return fmt.Errorf("dialect %T does not support RETURNING", q.dialect)
+	}
+	return nil
+}
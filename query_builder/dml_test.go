@@ -0,0 +1,114 @@
+package query_builder
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestDMLAlias(t *testing.T) {
+	byID := And(F("u.id", "=", 1))
+	tests := []struct {
+		name    string
+		dialect Dialect
+		build   func(q *Query) (string, []interface{}, error)
+		want    string
+	}{
+		{"postgres update", PostgresDialect{}, func(q *Query) (string, []interface{}, error) {
+			return q.Update("users", "u").Set("name", "x").Where(byID).Build()
+		}, `UPDATE "users" "u" SET "name" = $1 WHERE "u"."id" = $2`},
+		{"postgres delete", PostgresDialect{}, func(q *Query) (string, []interface{}, error) {
+			return q.Delete("users", "u").Where(byID).Returning("id").Build()
+		}, `DELETE FROM "users" "u" WHERE "u"."id" = $1 RETURNING "id"`},
+		{"sqlite update", SQLiteDialect{}, func(q *Query) (string, []interface{}, error) {
+			return q.Update("users", "u").Set("name", "x").Where(byID).Build()
+		}, `UPDATE "users" AS "u" SET "name" = ? WHERE "u"."id" = ?`},
+		{"sqlite delete", SQLiteDialect{}, func(q *Query) (string, []interface{}, error) {
+			return q.Delete("users", "u").Where(byID).Returning("id").Build()
+		}, `DELETE FROM "users" AS "u" WHERE "u"."id" = ? RETURNING "id"`},
+		{"sqlite without alias", SQLiteDialect{}, func(q *Query) (string, []interface{}, error) {
+			return q.Delete("users", "").Where(And(F("users.id", "=", 1))).Build()
+		}, `DELETE FROM "users" WHERE "users"."id" = ?`},
+		{"sqlserver update", SQLServerDialect{}, func(q *Query) (string, []interface{}, error) {
+			return q.Update("users", "u").Set("name", "x").Where(byID).Build()
+		}, `UPDATE [u] SET [name] = @p1 FROM [users] [u] WHERE [u].[id] = @p2`},
+		{"sqlserver delete", SQLServerDialect{}, func(q *Query) (string, []interface{}, error) {
+			return q.Delete("users", "u").Where(byID).Build()
+		}, `DELETE [u] FROM [users] [u] WHERE [u].[id] = @p1`},
+		{"sqlserver without alias", SQLServerDialect{}, func(q *Query) (string, []interface{}, error) {
+			return q.Update("users", "").Set("name", "x").Where(And(F("users.id", "=", 1))).Build()
+		}, `UPDATE [users] SET [name] = @p1 WHERE [users].[id] = @p2`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sql, _, err := tt.build(New(tt.dialect))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if sql != tt.want {
+				t.Errorf("sql =\n%s\nwant\n%s", sql, tt.want)
+			}
+		})
+	}
+}
+
+func TestSQLServerInsertRowLimit(t *testing.T) {
+	insert := func(rows int) *InsertQuery {
+		q := New(SQLServerDialect{}).Insert("users").Columns("id", "name")
+		for n := 0; n < rows; n++ {
+			q.Values(n, "x")
+		}
+		return q
+	}
+	if _, args, err := insert(1000).Build(); err != nil || len(args) != 2000 {
+		t.Errorf("1000 rows: %d args, error %v", len(args), err)
+	}
+	// 1001 rows bind only 2002 parameters, under the 2100 limit, but SQL Server
+	// still rejects more than 1000 rows in one VALUES list.
+	if _, _, err := insert(1001).Build(); err == nil || !strings.Contains(err.Error(), "insert has 1001 rows") {
+		t.Errorf("1001 rows: error %v, want a row limit error", err)
+	}
+}
+
+func TestInsert(t *testing.T) {
+	schema := map[string]map[string]bool{"users": {"id": true, "name": true}}
+	tests := []struct {
+		name    string
+		q       *InsertQuery
+		want    string
+		wantErr string
+	}{
+		{"single row", New(PostgresDialect{}).Insert("users").Columns("id", "name").Values(1, "ann"),
+			`INSERT INTO "users" ("id", "name") VALUES ($1, $2)`, ""},
+		{"several rows", New(MySQLDialect{}).Insert("users").Columns("id", "name").Values(1, "ann").Rows([]interface{}{2, "bob"}, []interface{}{3, "cy"}),
+			"INSERT INTO `users` (`id`, `name`) VALUES (?, ?), (?, ?), (?, ?)", ""},
+		{"oracle single row", New(OracleDialect{}).Insert("users").Columns("id").Values(1),
+			`INSERT INTO "users" ("id") VALUES (:1)`, ""},
+		{"oracle insert all", New(OracleDialect{}).Insert("users").Columns("id", "name").Values(1, "ann").Values(2, "bob"),
+			`INSERT ALL INTO "users" ("id", "name") VALUES (:1, :2) INTO "users" ("id", "name") VALUES (:3, :4) SELECT 1 FROM DUAL`, ""},
+		{"short row", New(PostgresDialect{}).Insert("users").Columns("id", "name").Values(1, "ann").Values(2),
+			"", "insert row 2 has 1 values, expected 2"},
+		{"no columns", New(PostgresDialect{}).Insert("users").Values(1), "", "insert columns required"},
+		{"no rows", New(PostgresDialect{}).Insert("users").Columns("id"), "", "insert values required"},
+		{"no table", New(PostgresDialect{}).Insert("").Columns("id").Values(1), "", "insert table required"},
+		{"duplicate column", New(PostgresDialect{}).Insert("users").Columns("id", "id").Values(1, 2), "", "duplicate insert column: id"},
+		{"unknown table", New(PostgresDialect{}).WithSchema(schema).Insert("admins").Columns("id").Values(1), "", "invalid insert table: admins"},
+		{"unknown column", New(PostgresDialect{}).WithSchema(schema).Insert("users").Columns("age").Values(1), "", "invalid column: users.age"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sql, _, err := tt.q.Build()
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("Build error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if sql != tt.want {
+				t.Errorf("sql =\n%s\nwant\n%s", sql, tt.want)
+			}
+		})
+	}
+}
@@ -23,5 +23,18 @@
 //
 // Schema validation is optional. When configured through WithSchema, every table
 // and column reference must exist in the provided map, which helps catch mistakes
-// early and prevents untrusted identifiers from being used.
+// early and prevents untrusted identifiers from being used. WithTypedSchema
+// additionally declares column types, so wrongly typed values are rejected or
+// coerced before they reach the driver. A typed schema can be derived from
+// tagged structs (SchemaFromStructs), read from a live database (LoadSchema),
+// or loaded from a JSON or YAML file (LoadSchemaFile, WatchSchemaFile).
+//
+// Every table, alias and column is quoted through Dialect.QuoteIdentifier, so
+// reserved words such as "order" are safe to use as column names. Call
+// UnquotedIdentifiers to render identifiers as given and keep the database's
+// case folding behavior.
+//
+// Dialect differences such as pagination syntax, RETURNING and upserts are
+// described by Capabilities. Custom dialects opt in to features by
+// implementing Capable; Build returns an error for anything a dialect lacks.
 package query_builder
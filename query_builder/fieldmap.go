@@ -0,0 +1,138 @@
+package query_builder
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Field describes a public API field and what clients may do with it.
+type Field struct {
+	Column    ColumnRef // Column the field maps to
+	Operators []string  // Operators clients may filter with; empty allows every operator
+	Sortable  bool      // If true, clients may sort by the field
+}
+
+// FieldMap maps public API field names, such as "createdAt", to columns.
+type FieldMap map[string]Field
+
+// FieldError reports a public field name that is unknown or used in a way its
+// Field does not allow.
+//
+// The message names only the public field and operator, never the underlying
+// column, so it is safe to return to API clients. Use errors.As to detect it.
+// Err holds the underlying error, if any, for server-side logging.
+type FieldError struct {
+	Field  string // Public field name as given by the client
+	Reason string // What is wrong, e.g. "unknown field"
+	Err    error  // Underlying error; not part of the message
+}
+
+// Error returns the reason followed by the field name.
+func (e *FieldError) Error() string {
+	return fmt.Sprintf("%s: %s", e.Reason, e.Field)
+}
+
+// Unwrap returns the underlying error.
+func (e *FieldError) Unwrap() error {
+	return e.Err
+}
+
+// WithFieldMap sets the public field names accepted by FF, SelectFields and
+// OrderByField.
+//
+// Call it before SelectFields and OrderByField, which resolve fields immediately.
+func (q *Query) WithFieldMap(fields FieldMap) *Query {
+	q.fieldMap = fields
+	return q
+}
+
+// FF constructs a Filter on a public field, resolved through the query's
+// FieldMap when the query is built.
+func FF(field string, op string, val interface{}) Filter {
+	return Filter{Field: field, Op: op, Value: val}
+}
+
+// SelectFields adds the columns of one or more public fields as projections.
+func (q *Query) SelectFields(fields ...string) *Query {
+	for _, name := range fields {
+		f, err := q.lookupField(name)
+		if err != nil {
+			q.errors = append(q.errors, err)
+			continue
+		}
+		q.projections = append(q.projections, projection{Column: f.Column})
+	}
+	return q
+}
+
+// OrderByField appends a sort on a public field, which must be Sortable.
+//
+// dir should be ASC or DESC.
+func (q *Query) OrderByField(field string, dir string) *Query {
+	f, err := q.lookupField(field)
+	if err != nil {
+		q.errors = append(q.errors, err)
+		return q
+	}
+	if !f.Sortable {
+		q.errors = append(q.errors, &FieldError{Field: field, Reason: "field is not sortable"})
+		return q
+	}
+	q.sorts = append(q.sorts, Sort{Column: f.Column, Dir: strings.ToUpper(dir)})
+	return q
+}
+
+// lookupField returns the Field registered for a public name.
+func (q *Query) lookupField(name string) (Field, error) {
+	f, ok := q.fieldMap[name]
+	if !ok {
+		return Field{}, &FieldError{Field: name, Reason: "unknown field"}
+	}
+	return f, nil
+}
+
+// resolveField sets a filter's column from its public field, checking that
+// the field allows the operator the caller wrote.
+//
+// Normalize may have replaced the operator with its negation; the check uses
+// the original, since that is what the client sent.
+func (q *Query) resolveField(f Filter) (Filter, error) {
+	field, err := q.lookupField(f.Field)
+	if err != nil {
+		return Filter{}, err
+	}
+	op := f.Op
+	if f.clientOp != "" {
+		op = f.clientOp
+	}
+	if len(field.Operators) > 0 {
+		allowed := false
+		for _, o := range field.Operators {
+			if strings.EqualFold(o, op) {
+				allowed = true
+				break
+			}
+		}
+		if !allowed {
+			return Filter{}, &FieldError{Field: f.Field, Reason: fmt.Sprintf("operator %s not allowed for field", strings.ToUpper(op))}
+		}
+	}
+	f.Column = field.Column
+	return f, nil
+}
+
+// fieldFilterError wraps an error from a resolved field filter in a
+// FieldError, so the message names the public field rather than its column.
+func fieldFilterError(field string, err error) error {
+	var fe *FieldError
+	if errors.As(err, &fe) {
+		return err
+	}
+	reason := "invalid filter for field"
+	var ve *valueError
+	if errors.As(err, &ve) {
+		reason = fmt.Sprintf("invalid value for field (%s)", ve.reason)
+	}
+	return &FieldError{Field: field, Reason: reason, Err: err}
+}
@@ -0,0 +1,146 @@
+package query_builder
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+var testFields = FieldMap{
+	"name":      {Column: Col("u.name"), Sortable: true},
+	"createdAt": {Column: Col("u.created_at"), Operators: []string{">", "<"}, Sortable: true},
+	"email":     {Column: Col("u.email"), Operators: []string{"="}},
+}
+
+var testFieldSchema = Schema{"users": {
+	"name":       {Type: TypeText},
+	"created_at": {Type: TypeTimestamp},
+	"email":      {Type: TypeText},
+}}
+
+func fieldQuery() *Query {
+	return New(PostgresDialect{}).WithTypedSchema(testFieldSchema).WithFieldMap(testFields).From("users", "u")
+}
+
+func TestFieldMap(t *testing.T) {
+	created := time.Date(2026, 10, 16, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name string
+		q    *Query
+		want string
+		args []interface{}
+	}{
+		{"filter select and sort",
+			fieldQuery().SelectFields("name", "createdAt").Where(And(FF("createdAt", ">", "2026-10-16T00:00:00Z"), FF("name", "LIKE", "a%"))).OrderByField("createdAt", "desc"),
+			`SELECT "u"."name", "u"."created_at" FROM "users" "u" WHERE "u"."created_at" > $1 AND "u"."name" LIKE $2 ORDER BY "u"."created_at" DESC`,
+			[]interface{}{created, "a%"}},
+		{"operator checked before normalization",
+			fieldQuery().Where(Not(FF("createdAt", ">", created)).Normalize()),
+			`SELECT "u".* FROM "users" "u" WHERE "u"."created_at" <= $1`,
+			[]interface{}{created}},
+		{"mixed with columns",
+			fieldQuery().Where(Or(FF("email", "=", "a@b.c"), F("u.name", "IS NULL", nil))),
+			`SELECT "u".* FROM "users" "u" WHERE "u"."email" = $1 OR "u"."name" IS NULL`,
+			[]interface{}{"a@b.c"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sql, args, err := tt.q.Build()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if sql != tt.want {
+				t.Errorf("sql =\n%s\nwant\n%s", sql, tt.want)
+			}
+			if !reflect.DeepEqual(args, tt.args) {
+				t.Errorf("args = %#v, want %#v", args, tt.args)
+			}
+		})
+	}
+}
+
+func TestFieldMapErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		q    *Query
+		want string
+	}{
+		{"unknown filter field", fieldQuery().Where(And(FF("password", "=", "x"))), "unknown field: password"},
+		{"unknown select field", fieldQuery().SelectFields("password"), "unknown field: password"},
+		{"unknown sort field", fieldQuery().OrderByField("password", "ASC"), "unknown field: password"},
+		{"not sortable", fieldQuery().OrderByField("email", "ASC"), "field is not sortable: email"},
+		{"operator not allowed", fieldQuery().Where(And(FF("email", "LIKE", "%"))), "operator LIKE not allowed for field: email"},
+		{"negated operator not allowed", fieldQuery().Where(Not(FF("email", "!=", "x")).Normalize()), "operator != not allowed for field: email"},
+		{"uncoercible value", fieldQuery().Where(And(FF("createdAt", ">", "garbage"))), "invalid value for field (expected timestamp, got string): createdAt"},
+		{"nil value", fieldQuery().Where(And(FF("name", "=", nil))), "invalid value for field (nil never matches =, use IS NULL): name"},
+		{"invalid operator", fieldQuery().Where(And(FF("name", "~", "x"))), "invalid filter for field: name"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, _, err := tt.q.Build()
+			var fe *FieldError
+			if !errors.As(err, &fe) || err.Error() != tt.want {
+				t.Fatalf("Build error = %v, want FieldError %q", err, tt.want)
+			}
+			// The message is shown to clients and must not name a column.
+			if strings.Contains(err.Error(), "u.") || strings.Contains(err.Error(), "created_at") {
+				t.Errorf("error %q reveals a column", err)
+			}
+		})
+	}
+}
+
+func TestFieldMapNestedQueries(t *testing.T) {
+	fields := FieldMap{"name": {Column: Col("u.name")}}
+	schema := Schema{"users": {"name": {Type: TypeText}, "age": {Type: TypeInt}}}
+	outer := func() *Query {
+		return New(PostgresDialect{}).WithTypedSchema(schema).WithFieldMap(fields)
+	}
+	// inner filters on a public field and passes a string the typed schema must coerce.
+	inner := func() *Query {
+		return New(PostgresDialect{}).From("users", "u").Select("u.name").Where(And(FF("name", "=", "ann"), F("u.age", ">", "18")))
+	}
+
+	tests := []struct {
+		name  string
+		build func() (string, []interface{}, error)
+		want  string
+		args  []interface{}
+	}{
+		{"subquery", func() (string, []interface{}, error) {
+			return outer().From("users", "x").Where(And(F("x.name", "IN", inner()))).Build()
+		}, `SELECT "x".* FROM "users" "x" WHERE "x"."name" IN (SELECT "u"."name" FROM "users" "u" WHERE "u"."name" = $1 AND "u"."age" > $2)`,
+			[]interface{}{"ann", int64(18)}},
+		{"cte", func() (string, []interface{}, error) {
+			return outer().With("adults", inner()).From("adults", "a").Select("a.name").Build()
+		}, `WITH "adults" AS (SELECT "u"."name" FROM "users" "u" WHERE "u"."name" = $1 AND "u"."age" > $2) SELECT "a"."name" FROM "adults" "a"`,
+			[]interface{}{"ann", int64(18)}},
+		{"set operation part", func() (string, []interface{}, error) {
+			return outer().From("users", "u").Select("u.name").Union(inner()).Build()
+		}, `SELECT "u"."name" FROM "users" "u" UNION SELECT "u"."name" FROM "users" "u" WHERE "u"."name" = $1 AND "u"."age" > $2`,
+			[]interface{}{"ann", int64(18)}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sql, args, err := tt.build()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if sql != tt.want {
+				t.Errorf("sql =\n%s\nwant\n%s", sql, tt.want)
+			}
+			if !reflect.DeepEqual(args, tt.args) {
+				t.Errorf("args = %#v, want %#v", args, tt.args)
+			}
+		})
+	}
+
+	// Without a field map anywhere, the public name stays unknown.
+	_, _, err := New(PostgresDialect{}).With("adults", inner()).From("adults", "a").Build()
+	var fe *FieldError
+	if !errors.As(err, &fe) || fe.Field != "name" {
+		t.Errorf("Build error = %v, want a FieldError for name", err)
+	}
+}
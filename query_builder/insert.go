@@ -0,0 +1,195 @@
+package query_builder
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// InsertQuery builds a SQL INSERT statement.
+//
+// An InsertQuery is created with Query.Insert and shares the dialect, schema
+// and quoting settings of the Query it was created from.
+type InsertQuery struct {
+	parent  *Query          // Query providing dialect, schema and quoting settings
+	table   string          // Target table
+	columns []string        // Columns to insert into, in order
+	rows    [][]interface{} // Value rows, each matching columns by position
+	upsert  *upsert         // Conflict handling; nil for a plain INSERT
+}
+
+// Insert returns an InsertQuery that writes into table.
+//
+// The returned builder validates columns against the schema set with WithSchema.
+func (q *Query) Insert(table string) *InsertQuery {
+	return &InsertQuery{parent: q, table: table}
+}
+
+// Columns appends the columns to insert into.
+//
+// Column names are plain names without a table alias.
+func (i *InsertQuery) Columns(columns ...string) *InsertQuery {
+	i.columns = append(i.columns, columns...)
+	return i
+}
+
+// Values appends a single row of values.
+//
+// Values are matched to Columns by position.
+func (i *InsertQuery) Values(values ...interface{}) *InsertQuery {
+	i.rows = append(i.rows, values)
+	return i
+}
+
+// Rows appends several rows of values at once.
+func (i *InsertQuery) Rows(rows ...[]interface{}) *InsertQuery {
+	i.rows = append(i.rows, rows...)
+	return i
+}
+
+// Build renders the INSERT statement and bound arguments.
+//
+// Every row must provide exactly one value per column.
+func (i *InsertQuery) Build() (string, []interface{}, error) {
+	if err := checkDialect(i.parent.dialect); err != nil {
+		return "", nil, err
+	}
+	sql, args, err := i.build()
+	if err != nil {
+		return "", nil, err
+	}
+	if err := checkParamLimit(i.parent.dialect, args); err != nil {
+		return "", nil, err
+	}
+	return sql, args, nil
+}
+
+// build renders the INSERT or upsert statement without the parameter limit check.
+func (i *InsertQuery) build() (string, []interface{}, error) {
+	if err := i.validate(); err != nil {
+		return "", nil, err
+	}
+	rows, err := i.coerceRows()
+	if err != nil {
+		return "", nil, err
+	}
+	// Render from a copy so the caller's rows are left as given.
+	typed := *i
+	typed.rows = rows
+	return typed.render()
+}
+
+// render writes the INSERT or upsert statement for validated rows.
+func (i *InsertQuery) render() (string, []interface{}, error) {
+	if i.upsert != nil {
+		return i.buildUpsert()
+	}
+
+	var sb strings.Builder
+	var args []interface{}
+
+	// Dialects without a multi-row VALUES list, such as Oracle, use INSERT ALL.
+	if i.parent.caps().InsertAll && len(i.rows) > 1 {
+		target := i.target()
+		sb.WriteString("INSERT ALL")
+		for _, row := range i.rows {
+			sb.WriteString(fmt.Sprintf(" INTO %s VALUES %s", target, i.parent.bindRow(row, &args)))
+		}
+		sb.WriteString(" SELECT 1 FROM DUAL")
+		return sb.String(), args, nil
+	}
+
+	i.writeValues(&sb, &args)
+	return sb.String(), args, nil
+}
+
+// writeValues renders INSERT INTO table (columns) VALUES with one tuple per row.
+func (i *InsertQuery) writeValues(sb *strings.Builder, args *[]interface{}) {
+	sb.WriteString("INSERT INTO " + i.target() + " VALUES ")
+	var tuples []string
+	for _, row := range i.rows {
+		tuples = append(tuples, i.parent.bindRow(row, args))
+	}
+	sb.WriteString(strings.Join(tuples, ", "))
+}
+
+// target returns the quoted table followed by its quoted column list.
+func (i *InsertQuery) target() string {
+	return fmt.Sprintf("%s (%s)", i.parent.quoteIdent(i.table), strings.Join(i.quotedColumns(), ", "))
+}
+
+// validate checks the target table, columns and row shapes.
+func (i *InsertQuery) validate() error {
+	if len(i.parent.errors) > 0 {
+		return i.parent.errors[0]
+	}
+	if i.table == "" {
+		return errors.New("insert table required")
+	}
+	if len(i.columns) == 0 {
+		return errors.New("insert columns required")
+	}
+	if len(i.rows) == 0 {
+		return errors.New("insert values required")
+	}
+
+	schema := i.parent.allowedSchema
+	if schema != nil {
+		if _, ok := schema[i.table]; !ok {
+			return fmt.Errorf("invalid insert table: %s", i.table)
+		}
+	}
+	seen := make(map[string]bool)
+	for _, c := range i.columns {
+		if seen[c] {
+			return fmt.Errorf("duplicate insert column: %s", c)
+		}
+		seen[c] = true
+		if schema != nil && !schema[i.table][c] {
+			return fmt.Errorf("invalid column: %s.%s", i.table, c)
+		}
+	}
+	for n, row := range i.rows {
+		if len(row) != len(i.columns) {
+			return fmt.Errorf("insert row %d has %d values, expected %d", n+1, len(row), len(i.columns))
+		}
+	}
+	if limit := i.parent.caps().MaxInsertRows; limit > 0 && len(i.rows) > limit {
+		return fmt.Errorf("insert has %d rows, dialect %T allows at most %d per statement", len(i.rows), i.parent.dialect, limit)
+	}
+	return nil
+}
+
+// coerceRows returns the rows with every value checked against the typed schema.
+func (i *InsertQuery) coerceRows() ([][]interface{}, error) {
+	if i.parent.typedSchema == nil {
+		return i.rows, nil
+	}
+	rows := make([][]interface{}, len(i.rows))
+	for n, row := range i.rows {
+		rows[n] = make([]interface{}, len(row))
+		for c, v := range row {
+			val, err := i.parent.coerceWrite(i.table, i.columns[c], v)
+			if err != nil {
+				return nil, err
+			}
+			rows[n][c] = val
+		}
+	}
+	return rows, nil
+}
+
+// quotedColumns returns the insert columns quoted for the dialect.
+func (i *InsertQuery) quotedColumns() []string {
+	return i.parent.quoteIdents(i.columns)
+}
+
+// bindRow appends row to args and returns its parenthesized placeholder list.
+func (q *Query) bindRow(row []interface{}, args *[]interface{}) string {
+	placeholders := make([]string, len(row))
+	for n, v := range row {
+		*args = append(*args, v)
+		placeholders[n] = q.dialect.Placeholder(len(*args))
+	}
+	return "(" + strings.Join(placeholders, ", ") + ")"
+}
@@ -0,0 +1,411 @@
+package query_builder
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"path"
+	"strings"
+)
+
+// LoadOption configures LoadSchema.
+type LoadOption func(*loadConfig)
+
+// loadConfig holds the settings collected from LoadOptions.
+type loadConfig struct {
+	include []string // Table name patterns to load; empty loads every table
+	exclude []string // Table name patterns to skip
+	schema  string   // Database schema (or Oracle owner) to read; empty means the current one
+}
+
+// IncludeTables limits LoadSchema to tables matching any of the patterns.
+//
+// Patterns use path.Match syntax, e.g. "user*", and are matched against the
+// table name exactly as the database reports it.
+func IncludeTables(patterns ...string) LoadOption {
+	return func(c *loadConfig) {
+		c.include = append(c.include, patterns...)
+	}
+}
+
+// ExcludeTables skips tables matching any of the patterns, even when included.
+func ExcludeTables(patterns ...string) LoadOption {
+	return func(c *loadConfig) {
+		c.exclude = append(c.exclude, patterns...)
+	}
+}
+
+// InDatabaseSchema reads tables from the named schema (the owner on Oracle)
+// instead of the connection's current one. It is ignored for SQLite.
+func InDatabaseSchema(name string) LoadOption {
+	return func(c *loadConfig) {
+		c.schema = name
+	}
+}
+
+// loadedColumn is one column read from the database catalog.
+type loadedColumn struct {
+	Table  string // Table name
+	Name   string // Column name
+	Column Column // Type and nullability
+}
+
+// loadedUnique is one column of a primary key or unique constraint.
+type loadedUnique struct {
+	Table      string // Table name
+	Constraint string // Constraint or index name
+	Column     string // Column name
+}
+
+// LoadSchema builds a typed Schema by reading the catalog of a live database.
+//
+// Postgres, MySQL and SQL Server are read through information_schema, Oracle
+// through ALL_TAB_COLUMNS and SQLite through pragma_table_info. Columns that
+// form a single-column primary key or unique constraint are marked Unique.
+// Only database/sql is used, so the caller opens db with any driver.
+func LoadSchema(ctx context.Context, db *sql.DB, dialect Dialect, opts ...LoadOption) (Schema, error) {
+	var cfg loadConfig
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	for _, p := range append(append([]string{}, cfg.include...), cfg.exclude...) {
+		if _, err := path.Match(p, ""); err != nil {
+			return nil, fmt.Errorf("invalid table pattern: %s", p)
+		}
+	}
+
+	var cols []loadedColumn
+	var uniques []loadedUnique
+	var err error
+	switch dialect.(type) {
+	case PostgresDialect, MySQLDialect, SQLServerDialect:
+		cols, uniques, err = loadInformationSchema(ctx, db, dialect, cfg.schema)
+	case OracleDialect:
+		cols, uniques, err = loadOracleSchema(ctx, db, cfg.schema)
+	case SQLiteDialect:
+		cols, uniques, err = loadSQLiteSchema(ctx, db)
+	default:
+		return nil, fmt.Errorf("schema introspection not supported for dialect %T", dialect)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("load schema: %v", err)
+	}
+
+	schema := make(Schema)
+	for _, c := range cols {
+		if !cfg.wants(c.Table) {
+			continue
+		}
+		if schema[c.Table] == nil {
+			schema[c.Table] = make(Table)
+		}
+		schema[c.Table][c.Name] = c.Column
+	}
+
+	// Only a constraint on exactly one column makes that column unique.
+	type key struct{ table, constraint string }
+	members := make(map[key][]string)
+	for _, u := range uniques {
+		k := key{u.Table, u.Constraint}
+		members[k] = append(members[k], u.Column)
+	}
+	for k, cols := range members {
+		if len(cols) != 1 {
+			continue
+		}
+		if col, ok := schema[k.table][cols[0]]; ok {
+			col.Unique = true
+			schema[k.table][cols[0]] = col
+		}
+	}
+	return schema, nil
+}
+
+// wants reports whether table passes the include and exclude patterns.
+func (c loadConfig) wants(table string) bool {
+	if len(c.include) > 0 && !matchAny(c.include, table) {
+		return false
+	}
+	return !matchAny(c.exclude, table)
+}
+
+// matchAny reports whether name matches any of the path.Match patterns.
+func matchAny(patterns []string, name string) bool {
+	for _, p := range patterns {
+		if ok, _ := path.Match(p, name); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// loadInformationSchema reads columns and key constraints from information_schema.
+func loadInformationSchema(ctx context.Context, db *sql.DB, dialect Dialect, schemaName string) ([]loadedColumn, []loadedUnique, error) {
+	current, typeCol := "current_schema()", "data_type"
+	switch dialect.(type) {
+	case MySQLDialect:
+		// column_type keeps the display width and enum values, e.g. enum('a','b').
+		current, typeCol = "DATABASE()", "column_type"
+	case SQLServerDialect:
+		current = "SCHEMA_NAME()"
+	}
+	var target interface{}
+	if schemaName != "" {
+		target = schemaName
+	}
+	p := dialect.Placeholder(1)
+
+	rows, err := db.QueryContext(ctx, fmt.Sprintf(`SELECT c.table_name, c.column_name, c.%s, c.is_nullable
+FROM information_schema.columns c
+JOIN information_schema.tables t ON t.table_schema = c.table_schema AND t.table_name = c.table_name
+WHERE c.table_schema = COALESCE(%s, %s) AND t.table_type = 'BASE TABLE'
+ORDER BY c.table_name, c.ordinal_position`, typeCol, p, current), target)
+	if err != nil {
+		return nil, nil, err
+	}
+	var cols []loadedColumn
+	for rows.Next() {
+		var c loadedColumn
+		var dataType, nullable string
+		if err := rows.Scan(&c.Table, &c.Name, &dataType, &nullable); err != nil {
+			rows.Close()
+			return nil, nil, err
+		}
+		c.Column = sqlColumn(dataType, false)
+		c.Column.Nullable = strings.EqualFold(nullable, "YES")
+		cols = append(cols, c)
+	}
+	if err := closeRows(rows); err != nil {
+		return nil, nil, err
+	}
+
+	rows, err = db.QueryContext(ctx, fmt.Sprintf(`SELECT tc.table_name, tc.constraint_name, kcu.column_name
+FROM information_schema.table_constraints tc
+JOIN information_schema.key_column_usage kcu
+  ON kcu.constraint_schema = tc.constraint_schema AND kcu.constraint_name = tc.constraint_name AND kcu.table_name = tc.table_name
+WHERE tc.table_schema = COALESCE(%s, %s) AND tc.constraint_type IN ('PRIMARY KEY', 'UNIQUE')`, p, current), target)
+	if err != nil {
+		return nil, nil, err
+	}
+	uniques, err := scanUniques(rows)
+	return cols, uniques, err
+}
+
+// loadOracleSchema reads columns and key constraints from Oracle's ALL_* views.
+func loadOracleSchema(ctx context.Context, db *sql.DB, owner string) ([]loadedColumn, []loadedUnique, error) {
+	var target interface{}
+	if owner != "" {
+		target = owner
+	}
+	rows, err := db.QueryContext(ctx, `SELECT c.table_name, c.column_name, c.data_type, c.data_scale, c.nullable
+FROM all_tab_columns c
+JOIN all_tables t ON t.owner = c.owner AND t.table_name = c.table_name
+WHERE c.owner = NVL(:1, USER)
+ORDER BY c.table_name, c.column_id`, target)
+	if err != nil {
+		return nil, nil, err
+	}
+	var cols []loadedColumn
+	for rows.Next() {
+		var c loadedColumn
+		var dataType, nullable string
+		var scale sql.NullInt64
+		if err := rows.Scan(&c.Table, &c.Name, &dataType, &scale, &nullable); err != nil {
+			rows.Close()
+			return nil, nil, err
+		}
+		c.Column = sqlColumn(dataType, false)
+		// NUMBER holds integers only when its scale is zero.
+		if strings.EqualFold(dataType, "NUMBER") && scale.Valid && scale.Int64 == 0 {
+			c.Column.Type = TypeInt
+		}
+		c.Column.Nullable = nullable == "Y"
+		cols = append(cols, c)
+	}
+	if err := closeRows(rows); err != nil {
+		return nil, nil, err
+	}
+
+	rows, err = db.QueryContext(ctx, `SELECT cc.table_name, cc.constraint_name, cc.column_name
+FROM all_constraints c
+JOIN all_cons_columns cc ON cc.owner = c.owner AND cc.constraint_name = c.constraint_name
+WHERE c.owner = NVL(:1, USER) AND c.constraint_type IN ('P', 'U')`, target)
+	if err != nil {
+		return nil, nil, err
+	}
+	uniques, err := scanUniques(rows)
+	return cols, uniques, err
+}
+
+// loadSQLiteSchema reads columns and unique indexes with SQLite's table-valued
+// pragma functions, available since SQLite 3.16.
+func loadSQLiteSchema(ctx context.Context, db *sql.DB) ([]loadedColumn, []loadedUnique, error) {
+	rows, err := db.QueryContext(ctx, `SELECT name FROM sqlite_master WHERE type = 'table' AND name NOT LIKE 'sqlite\_%' ESCAPE '\' ORDER BY name`)
+	if err != nil {
+		return nil, nil, err
+	}
+	var tables []string
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			rows.Close()
+			return nil, nil, err
+		}
+		tables = append(tables, name)
+	}
+	if err := closeRows(rows); err != nil {
+		return nil, nil, err
+	}
+
+	var cols []loadedColumn
+	var uniques []loadedUnique
+	for _, table := range tables {
+		rows, err := db.QueryContext(ctx, `SELECT name, type, "notnull", pk FROM pragma_table_info(?)`, table)
+		if err != nil {
+			return nil, nil, err
+		}
+		for rows.Next() {
+			c := loadedColumn{Table: table}
+			var declared string
+			var notNull, pk int
+			if err := rows.Scan(&c.Name, &declared, &notNull, &pk); err != nil {
+				rows.Close()
+				return nil, nil, err
+			}
+			c.Column = sqlColumn(declared, true)
+			// Primary key columns never hold NULL in practice, even without NOT NULL.
+			c.Column.Nullable = notNull == 0 && pk == 0
+			cols = append(cols, c)
+			if pk > 0 {
+				uniques = append(uniques, loadedUnique{Table: table, Constraint: "primary key", Column: c.Name})
+			}
+		}
+		if err := closeRows(rows); err != nil {
+			return nil, nil, err
+		}
+
+		rows, err = db.QueryContext(ctx, `SELECT ?, il.name, ii.name
+FROM pragma_index_list(?) il JOIN pragma_index_info(il.name) ii
+WHERE il."unique" = 1 AND il.partial = 0 AND il.origin != 'pk'`, table, table)
+		if err != nil {
+			return nil, nil, err
+		}
+		idx, err := scanUniques(rows)
+		if err != nil {
+			return nil, nil, err
+		}
+		uniques = append(uniques, idx...)
+	}
+	return cols, uniques, nil
+}
+
+// scanUniques reads (table, constraint, column) rows and closes rows.
+func scanUniques(rows *sql.Rows) ([]loadedUnique, error) {
+	var uniques []loadedUnique
+	for rows.Next() {
+		var u loadedUnique
+		if err := rows.Scan(&u.Table, &u.Constraint, &u.Column); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		uniques = append(uniques, u)
+	}
+	return uniques, closeRows(rows)
+}
+
+// closeRows closes rows and reports any error from iterating them.
+func closeRows(rows *sql.Rows) error {
+	if err := rows.Err(); err != nil {
+		rows.Close()
+		return err
+	}
+	return rows.Close()
+}
+
+// sqlTypes maps lower-case database type names to column types.
+var sqlTypes = map[string]ColumnType{
+	"int": TypeInt, "integer": TypeInt, "smallint": TypeInt, "bigint": TypeInt, "tinyint": TypeInt,
+	"mediumint": TypeInt, "int2": TypeInt, "int4": TypeInt, "int8": TypeInt,
+	"serial": TypeInt, "smallserial": TypeInt, "bigserial": TypeInt,
+	"real": TypeFloat, "float": TypeFloat, "float4": TypeFloat, "float8": TypeFloat, "double": TypeFloat,
+	"double precision": TypeFloat, "numeric": TypeFloat, "decimal": TypeFloat, "number": TypeFloat,
+	"money": TypeFloat, "binary_float": TypeFloat, "binary_double": TypeFloat,
+	"text": TypeText, "char": TypeText, "varchar": TypeText, "nchar": TypeText, "nvarchar": TypeText,
+	"character": TypeText, "character varying": TypeText, "varchar2": TypeText, "nvarchar2": TypeText,
+	"clob": TypeText, "nclob": TypeText, "ntext": TypeText, "tinytext": TypeText, "mediumtext": TypeText,
+	"longtext": TypeText, "citext": TypeText,
+	"bool": TypeBool, "boolean": TypeBool, "bit": TypeBool,
+	"date": TypeTimestamp, "datetime": TypeTimestamp, "datetime2": TypeTimestamp,
+	"smalldatetime": TypeTimestamp, "datetimeoffset": TypeTimestamp, "timestamp": TypeTimestamp, "timestamptz": TypeTimestamp,
+	"uuid": TypeUUID, "uniqueidentifier": TypeUUID,
+	"json": TypeJSON, "jsonb": TypeJSON,
+	"enum": TypeEnum,
+}
+
+// sqlColumn maps a database type name, such as "varchar(255)",
+// "timestamp with time zone" or "enum('a','b')", to a column definition.
+//
+// affinity applies SQLite's rules for declared types no table lists, so
+// "UNSIGNED BIG INT" is an int and "VARYING CHARACTER" is text.
+func sqlColumn(declared string, affinity bool) Column {
+	declared = strings.TrimSpace(declared)
+	decl := strings.ToLower(declared)
+	// MySQL reports BOOLEAN columns as tinyint(1).
+	if decl == "tinyint(1)" {
+		return Column{Type: TypeBool}
+	}
+	if strings.HasPrefix(decl, "enum(") && strings.HasSuffix(decl, ")") {
+		return Column{Type: TypeEnum, Values: parseEnumValues(declared[5 : len(declared)-1])}
+	}
+
+	base := decl
+	if i := strings.IndexByte(base, '('); i >= 0 {
+		base = strings.TrimSpace(base[:i])
+	}
+	if t, ok := sqlTypes[base]; ok {
+		return Column{Type: t}
+	}
+	switch {
+	case strings.HasPrefix(base, "timestamp"):
+		return Column{Type: TypeTimestamp}
+	case strings.HasPrefix(base, "character varying"), strings.HasPrefix(base, "varchar"):
+		return Column{Type: TypeText}
+	case strings.HasSuffix(base, " unsigned"):
+		return sqlColumn(strings.TrimSuffix(base, " unsigned"), affinity)
+	}
+	if affinity {
+		switch {
+		case strings.Contains(base, "int"):
+			return Column{Type: TypeInt}
+		case strings.Contains(base, "char"), strings.Contains(base, "clob"), strings.Contains(base, "text"):
+			return Column{Type: TypeText}
+		case strings.Contains(base, "real"), strings.Contains(base, "floa"), strings.Contains(base, "doub"):
+			return Column{Type: TypeFloat}
+		}
+	}
+	return Column{Type: TypeAny}
+}
+
+// parseEnumValues splits a MySQL enum body such as 'a','b' into its values.
+func parseEnumValues(body string) []string {
+	var values []string
+	var cur strings.Builder
+	inQuote := false
+	for i := 0; i < len(body); i++ {
+		ch := body[i]
+		switch {
+		case ch == '\'' && inQuote && i+1 < len(body) && body[i+1] == '\'':
+			cur.WriteByte('\'')
+			i++
+		case ch == '\'':
+			inQuote = !inQuote
+			if !inQuote {
+				values = append(values, cur.String())
+				cur.Reset()
+			}
+		case inQuote:
+			cur.WriteByte(ch)
+		}
+	}
+	return values
+}
@@ -0,0 +1,122 @@
+//go:build sqlite
+
+// Run with: go test -tags sqlite ./query_builder
+// The driver needs cgo.
+
+package query_builder
+
+import (
+	"context"
+	"database/sql"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+func openSQLiteFile(t *testing.T, ddl ...string) *sql.DB {
+	t.Helper()
+	db, err := sql.Open("sqlite3", filepath.Join(t.TempDir(), "schema.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+	for _, stmt := range ddl {
+		if _, err := db.Exec(stmt); err != nil {
+			t.Fatalf("%s: %v", stmt, err)
+		}
+	}
+	return db
+}
+
+func TestLoadSchemaSQLite(t *testing.T) {
+	db := openSQLiteFile(t,
+		`CREATE TABLE users (
+			id INTEGER PRIMARY KEY,
+			email VARCHAR(255) NOT NULL UNIQUE,
+			name TEXT,
+			score DOUBLE,
+			active BOOLEAN NOT NULL,
+			created_at TIMESTAMP,
+			tenant INT NOT NULL,
+			handle TEXT NOT NULL,
+			UNIQUE (tenant, handle)
+		)`,
+		`CREATE TABLE orders (id INTEGER NOT NULL, line INTEGER NOT NULL, total NUMERIC, PRIMARY KEY (id, line))`,
+		`CREATE TABLE audit_log (payload BLOB)`,
+		`CREATE UNIQUE INDEX users_name_active ON users (name) WHERE active`,
+	)
+
+	schema, err := LoadSchema(context.Background(), db, SQLiteDialect{}, ExcludeTables("audit_*"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := Schema{
+		"users": {
+			"id":         {Type: TypeInt, Unique: true},
+			"email":      {Type: TypeText, Unique: true},
+			"name":       {Type: TypeText, Nullable: true},
+			"score":      {Type: TypeFloat, Nullable: true},
+			"active":     {Type: TypeBool},
+			"created_at": {Type: TypeTimestamp, Nullable: true},
+			"tenant":     {Type: TypeInt},
+			"handle":     {Type: TypeText},
+		},
+		// A composite primary key makes no single column unique.
+		"orders": {
+			"id":    {Type: TypeInt},
+			"line":  {Type: TypeInt},
+			"total": {Type: TypeFloat, Nullable: true},
+		},
+	}
+	if !reflect.DeepEqual(schema, want) {
+		t.Errorf("LoadSchema =\n%+v\nwant\n%+v", schema, want)
+	}
+
+	only, err := LoadSchema(context.Background(), db, SQLiteDialect{}, IncludeTables("ord*"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(only) != 1 || only["orders"] == nil {
+		t.Errorf("IncludeTables(\"ord*\") loaded %v", only)
+	}
+}
+
+func TestLoadSchemaSQLiteQueries(t *testing.T) {
+	db := openSQLiteFile(t,
+		`CREATE TABLE users (id INTEGER PRIMARY KEY, name TEXT NOT NULL, age INTEGER)`,
+		`INSERT INTO users (id, name, age) VALUES (1, 'ann', 30), (2, 'bob', 17)`,
+	)
+	schema, err := LoadSchema(context.Background(), db, SQLiteDialect{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The loaded types coerce the string "18" so SQLite compares it as a number.
+	query, args, err := New(SQLiteDialect{}).WithTypedSchema(schema).
+		From("users", "u").Select("u.name").Where(And(F("u.age", ">=", "18"))).Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var name string
+	if err := db.QueryRow(query, args...).Scan(&name); err != nil {
+		t.Fatalf("%s: %v", query, err)
+	}
+	if name != "ann" {
+		t.Errorf("got %q, want ann", name)
+	}
+
+	// Aliased DELETE ... RETURNING must be accepted by SQLite itself.
+	query, args, err = New(SQLiteDialect{}).WithTypedSchema(schema).
+		Delete("users", "u").Where(And(F("u.id", "=", 2))).Returning("name").Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.QueryRow(query, args...).Scan(&name); err != nil {
+		t.Fatalf("%s: %v", query, err)
+	}
+	if name != "bob" {
+		t.Errorf("deleted %q, want bob", name)
+	}
+}
@@ -0,0 +1,57 @@
+package query_builder
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSQLColumn(t *testing.T) {
+	tests := []struct {
+		declared string
+		affinity bool
+		want     Column
+	}{
+		{"integer", false, Column{Type: TypeInt}},
+		{"BIGINT", false, Column{Type: TypeInt}},
+		{"int unsigned", false, Column{Type: TypeInt}},
+		{"tinyint(1)", false, Column{Type: TypeBool}},
+		{"tinyint(4)", false, Column{Type: TypeInt}},
+		{"varchar(255)", false, Column{Type: TypeText}},
+		{"character varying(40)", false, Column{Type: TypeText}},
+		{"numeric(10,2)", false, Column{Type: TypeFloat}},
+		{"double precision", false, Column{Type: TypeFloat}},
+		{"timestamp with time zone", false, Column{Type: TypeTimestamp}},
+		{"timestamp(6) without time zone", false, Column{Type: TypeTimestamp}},
+		{"uniqueidentifier", false, Column{Type: TypeUUID}},
+		{"jsonb", false, Column{Type: TypeJSON}},
+		{" enum('active','disabled') ", false, Column{Type: TypeEnum, Values: []string{"active", "disabled"}}},
+		{"geometry", false, Column{Type: TypeAny}},
+		{"UNSIGNED BIG INT", false, Column{Type: TypeAny}},
+		{"UNSIGNED BIG INT", true, Column{Type: TypeInt}},
+		{"VARYING CHARACTER(20)", true, Column{Type: TypeText}},
+		{"DOUBLE FLOAT", true, Column{Type: TypeFloat}},
+		{"", true, Column{Type: TypeAny}},
+	}
+	for _, tt := range tests {
+		if got := sqlColumn(tt.declared, tt.affinity); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("sqlColumn(%q, %v) = %+v, want %+v", tt.declared, tt.affinity, got, tt.want)
+		}
+	}
+}
+
+func TestParseEnumValues(t *testing.T) {
+	tests := []struct {
+		body string
+		want []string
+	}{
+		{"'a','b'", []string{"a", "b"}},
+		{"'it''s', 'x,y'", []string{"it's", "x,y"}},
+		{"''", []string{""}},
+		{"", nil},
+	}
+	for _, tt := range tests {
+		if got := parseEnumValues(tt.body); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("parseEnumValues(%q) = %q, want %q", tt.body, got, tt.want)
+		}
+	}
+}
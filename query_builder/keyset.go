@@ -0,0 +1,162 @@
+package query_builder
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// WithUniqueColumns declares which columns hold unique values.
+//
+// The expected format matches WithSchema: map[tableName][columnName]bool.
+// When schema validation is enabled, keyset pagination requires the last sort
+// column to be declared unique, here or through Column.Unique in a typed
+// schema, so rows with equal sort values are neither skipped nor repeated.
+func (q *Query) WithUniqueColumns(unique map[string]map[string]bool) *Query {
+	q.uniqueColumns = unique
+	return q
+}
+
+// KeysetBefore configures cursor-based paging towards the previous page.
+//
+// lastSeen holds the sort values of the first row of the current page, keyed
+// like KeysetPagination. Build flips both the keyset comparison and the ORDER
+// BY directions, so the database returns the previous page in reverse order.
+// When IsBackward reports true, reverse the fetched rows (for example with
+// slices.Reverse) to restore the query's declared order.
+func (q *Query) KeysetBefore(lastSeen map[string]interface{}) *Query {
+	q.pagination = Pagination{
+		Type:     "keyset",
+		LastSeen: lastSeen,
+		Backward: true,
+	}
+	return q
+}
+
+// IsBackward reports whether the query fetches a previous keyset page, whose
+// rows come back in reverse order.
+func (q *Query) IsBackward() bool {
+	return q.pagination.Type == "keyset" && q.pagination.Backward
+}
+
+// buildKeysetPagination generates the cursor-based comparison for paging.
+//
+// Every sort column takes part in the comparison. When all columns sort in the
+// same direction and the dialect supports row values, the comparison is
+// rendered as "(a, b) > ($1, $2)"; otherwise it is expanded to
+// "(a > $1 OR (a = $2 AND b > $3))", which also handles mixed directions.
+func (q *Query) buildKeysetPagination(args *[]interface{}, aliasMap map[string]string, schema map[string]map[string]bool) (string, error) {
+	if q.pagination.Type != "keyset" {
+		return "", nil
+	}
+	if len(q.sorts) == 0 {
+		return "", errors.New("keyset pagination requires ORDER BY")
+	}
+	for _, s := range q.sorts {
+		// The keyset comparison cannot step past NULLs, so their placement must not matter.
+		if s.Nulls != "" {
+			return "", fmt.Errorf("keyset pagination does not support NULLS %s: %s.%s", strings.ToUpper(s.Nulls), s.Column.TableAlias, s.Column.ColumnName)
+		}
+	}
+	if err := q.validateKeysetSorts(aliasMap, schema); err != nil {
+		return "", err
+	}
+	lastSeen, err := q.lastSeen()
+	if err != nil {
+		return "", err
+	}
+	if len(lastSeen) == 0 {
+		return "", nil
+	}
+
+	cols := make([]string, len(q.sorts))
+	ops := make([]string, len(q.sorts))
+	vals := make([]interface{}, len(q.sorts))
+	uniform := true
+	for i, s := range q.sorts {
+		key := s.Column.TableAlias + "." + s.Column.ColumnName
+		val, ok := lastSeen[key]
+		if !ok {
+			return "", fmt.Errorf("keyset value missing for sort column: %s", key)
+		}
+		cols[i] = q.quoteCol(s.Column)
+		ops[i] = ">"
+		if strings.ToUpper(s.Dir) == "DESC" {
+			ops[i] = "<"
+		}
+		if q.pagination.Backward {
+			ops[i] = reverseComparison(ops[i])
+		}
+		vals[i] = val
+		if ops[i] != ops[0] {
+			uniform = false
+		}
+	}
+
+	if len(cols) == 1 {
+		*args = append(*args, vals[0])
+		return fmt.Sprintf("%s %s %s", cols[0], ops[0], q.dialect.Placeholder(len(*args))), nil
+	}
+
+	if uniform && q.caps().RowValues {
+		placeholders := make([]string, len(vals))
+		for i, v := range vals {
+			*args = append(*args, v)
+			placeholders[i] = q.dialect.Placeholder(len(*args))
+		}
+		return fmt.Sprintf("(%s) %s (%s)", strings.Join(cols, ", "), ops[0], strings.Join(placeholders, ", ")), nil
+	}
+
+	// Each occurrence gets its own argument, because not every driver lets a
+	// numbered placeholder be bound more than once.
+	bind := func(i int) string {
+		*args = append(*args, vals[i])
+		return q.dialect.Placeholder(len(*args))
+	}
+
+	var branches []string
+	for i := range cols {
+		var terms []string
+		for j := 0; j < i; j++ {
+			terms = append(terms, fmt.Sprintf("%s = %s", cols[j], bind(j)))
+		}
+		terms = append(terms, fmt.Sprintf("%s %s %s", cols[i], ops[i], bind(i)))
+		if len(terms) == 1 {
+			branches = append(branches, terms[0])
+		} else {
+			branches = append(branches, "("+strings.Join(terms, " AND ")+")")
+		}
+	}
+	return "(" + strings.Join(branches, " OR ") + ")", nil
+}
+
+// validateKeysetSorts ensures the sort list ends in a unique column.
+//
+// The check only applies when schema validation is enabled.
+func (q *Query) validateKeysetSorts(aliasMap map[string]string, schema map[string]map[string]bool) error {
+	if schema == nil {
+		return nil
+	}
+	last := q.sorts[len(q.sorts)-1].Column
+	table := aliasMap[last.TableAlias]
+	if !q.uniqueColumns[table][last.ColumnName] && !q.typedSchema[table][last.ColumnName].Unique {
+		return fmt.Errorf("keyset pagination requires the last sort column to be unique: %s.%s", last.TableAlias, last.ColumnName)
+	}
+	return nil
+}
+
+// reverseComparison returns the strict comparison pointing the other way.
+func reverseComparison(op string) string {
+	if op == ">" {
+		return "<"
+	}
+	return ">"
+}
+
+// reverseDir returns the opposite sort direction.
+func reverseDir(dir string) string {
+	if dir == "ASC" {
+		return "DESC"
+	}
+	return "ASC"
+}
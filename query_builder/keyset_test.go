@@ -0,0 +1,88 @@
+package query_builder
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestKeysetPagination(t *testing.T) {
+	seen := map[string]interface{}{"u.created_at": "2026-10-16", "u.id": 9}
+	page := func(d Dialect, dirs ...string) *Query {
+		return New(d).From("users", "u").Select("u.id").OrderBy("u.created_at", dirs[0]).OrderBy("u.id", dirs[1]).Limit(10)
+	}
+	tests := []struct {
+		name string
+		q    *Query
+		want string
+		args []interface{}
+	}{
+		{"single column", New(PostgresDialect{}).From("users", "u").Select("u.id").Where(And(F("u.age", ">", 18))).OrderBy("u.id", "ASC").KeysetPagination(map[string]interface{}{"u.id": 9}),
+			`SELECT "u"."id" FROM "users" "u" WHERE ("u"."age" > $1) AND "u"."id" > $2 ORDER BY "u"."id" ASC`, []interface{}{18, 9}},
+		{"row values", page(PostgresDialect{}, "DESC", "DESC").KeysetPagination(seen),
+			`SELECT "u"."id" FROM "users" "u" WHERE ("u"."created_at", "u"."id") < ($1, $2) ORDER BY "u"."created_at" DESC, "u"."id" DESC LIMIT $3`,
+			[]interface{}{"2026-10-16", 9, 10}},
+		{"expanded without row values", page(OracleDialect{}, "ASC", "ASC").KeysetPagination(seen),
+			`SELECT "u"."id" FROM "users" "u" WHERE ("u"."created_at" > :1 OR ("u"."created_at" = :2 AND "u"."id" > :3)) ORDER BY "u"."created_at" ASC, "u"."id" ASC FETCH NEXT :4 ROWS ONLY`,
+			[]interface{}{"2026-10-16", "2026-10-16", 9, 10}},
+		{"mixed directions", page(PostgresDialect{}, "DESC", "ASC").KeysetPagination(seen),
+			`SELECT "u"."id" FROM "users" "u" WHERE ("u"."created_at" < $1 OR ("u"."created_at" = $2 AND "u"."id" > $3)) ORDER BY "u"."created_at" DESC, "u"."id" ASC LIMIT $4`,
+			[]interface{}{"2026-10-16", "2026-10-16", 9, 10}},
+		{"backward", page(PostgresDialect{}, "DESC", "ASC").KeysetBefore(seen),
+			`SELECT "u"."id" FROM "users" "u" WHERE ("u"."created_at" > $1 OR ("u"."created_at" = $2 AND "u"."id" < $3)) ORDER BY "u"."created_at" ASC, "u"."id" DESC LIMIT $4`,
+			[]interface{}{"2026-10-16", "2026-10-16", 9, 10}},
+		{"first page", page(PostgresDialect{}, "ASC", "ASC").KeysetPagination(nil),
+			`SELECT "u"."id" FROM "users" "u" ORDER BY "u"."created_at" ASC, "u"."id" ASC LIMIT $1`, []interface{}{10}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sql, args, err := tt.q.Build()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if sql != tt.want {
+				t.Errorf("sql =\n%s\nwant\n%s", sql, tt.want)
+			}
+			if !reflect.DeepEqual(args, tt.args) {
+				t.Errorf("args = %#v, want %#v", args, tt.args)
+			}
+		})
+	}
+}
+
+func TestKeysetUniqueLastColumn(t *testing.T) {
+	schema := map[string]map[string]bool{"users": {"id": true, "created_at": true}}
+	seen := map[string]interface{}{"u.created_at": "2026-10-16", "u.id": 9}
+	tests := []struct {
+		name    string
+		q       *Query
+		wantErr string
+	}{
+		{"not unique", New(PostgresDialect{}).WithSchema(schema).From("users", "u").OrderBy("u.id", "ASC").OrderBy("u.created_at", "ASC").KeysetPagination(seen),
+			"last sort column to be unique: u.created_at"},
+		{"unique columns", New(PostgresDialect{}).WithSchema(schema).WithUniqueColumns(map[string]map[string]bool{"users": {"id": true}}).
+			From("users", "u").OrderBy("u.created_at", "ASC").OrderBy("u.id", "ASC").KeysetPagination(seen), ""},
+		{"typed schema", New(PostgresDialect{}).WithTypedSchema(Schema{"users": {"id": {Type: TypeInt, Unique: true}, "created_at": {}}}).
+			From("users", "u").OrderBy("u.created_at", "ASC").OrderBy("u.id", "ASC").KeysetPagination(seen), ""},
+		{"without schema", New(PostgresDialect{}).From("users", "u").OrderBy("u.id", "ASC").OrderBy("u.created_at", "ASC").KeysetPagination(seen), ""},
+		{"missing value", New(PostgresDialect{}).From("users", "u").OrderBy("u.name", "ASC").KeysetPagination(seen),
+			"keyset value missing for sort column: u.name"},
+		{"nulls placement", New(PostgresDialect{}).From("users", "u").OrderByNulls("u.id", "ASC", "LAST").KeysetPagination(seen),
+			"does not support NULLS LAST"},
+		{"no sort", New(PostgresDialect{}).From("users", "u").KeysetPagination(seen), "requires ORDER BY"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, _, err := tt.q.Build()
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("Build error = %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Build error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,112 @@
+package query_builder
+
+import (
+	"errors"
+	"strings"
+)
+
+// negatedOperators maps each operator to its logical opposite.
+//
+// Each pair treats NULL the same way, so swapping one for the other keeps
+// SQL's three-valued logic intact.
+var negatedOperators = map[string]string{
+	"=": "!=", "!=": "=", "<>": "=", "<": ">=", ">=": "<", ">": "<=", "<=": ">",
+	"LIKE": "NOT LIKE", "NOT LIKE": "LIKE", "ILIKE": "NOT ILIKE", "NOT ILIKE": "ILIKE",
+	"IN": "NOT IN", "NOT IN": "IN", "BETWEEN": "NOT BETWEEN", "NOT BETWEEN": "BETWEEN",
+	"IS": "IS NOT", "IS NOT": "IS", "IS NULL": "IS NOT NULL", "IS NOT NULL": "IS NULL",
+	"EXISTS": "NOT EXISTS", "NOT EXISTS": "EXISTS",
+}
+
+// Not returns a FilterGroup that negates item.
+//
+// item may be either a Filter or a *FilterGroup, and is rendered as
+// "NOT (...)". Use Normalize to push the negation into the comparisons.
+func Not(item interface{}) *FilterGroup {
+	return createGroup("NOT", item)
+}
+
+// Normalize returns an equivalent filter tree with every NOT pushed inward.
+//
+// Negated groups are rewritten with De Morgan's laws and negated filters use
+// the opposite operator, so "NOT (a = 1 OR b < 2)" becomes "a != 1 AND
+// b >= 2", which databases can match against indexes. Filters whose operator
+// has no opposite keep an explicit NOT. Filters on public fields are still
+// checked against the operator the client wrote. g itself is left unchanged.
+func (g *FilterGroup) Normalize() *FilterGroup {
+	if g == nil {
+		return nil
+	}
+	n := normalizeGroup(*g, false)
+	return &n
+}
+
+// normalizeGroup rewrites g, negating it when negate is set.
+func normalizeGroup(g FilterGroup, negate bool) FilterGroup {
+	op := strings.ToUpper(g.Operator)
+	if op == "NOT" {
+		// A malformed NOT is left for buildFilterGroup to reject.
+		if len(g.Filters)+len(g.Groups) != 1 {
+			return g
+		}
+		if len(g.Groups) == 1 {
+			return normalizeGroup(g.Groups[0], !negate)
+		}
+		return normalizeGroup(FilterGroup{Operator: "AND", Filters: g.Filters}, !negate)
+	}
+	if op != "AND" && op != "OR" {
+		return g
+	}
+
+	if negate {
+		if op == "AND" {
+			op = "OR"
+		} else {
+			op = "AND"
+		}
+	}
+	out := FilterGroup{Operator: op}
+	for _, f := range g.Filters {
+		if !negate {
+			out.Filters = append(out.Filters, f)
+			continue
+		}
+		if neg, ok := negatedOperators[strings.ToUpper(f.Op)]; ok {
+			if f.clientOp == "" {
+				f.clientOp = f.Op
+			}
+			f.Op = neg
+			out.Filters = append(out.Filters, f)
+			continue
+		}
+		out.Groups = append(out.Groups, *Not(f))
+	}
+	for _, sub := range g.Groups {
+		out.Groups = append(out.Groups, normalizeGroup(sub, negate))
+	}
+	return out
+}
+
+// buildNot renders a NOT group, which must wrap exactly one filter or group.
+func (q *Query) buildNot(g FilterGroup, args *[]interface{}, aliasMap map[string]string, depth int, schema map[string]map[string]bool, aggs map[string]Aggregate) (string, error) {
+	if len(g.Filters)+len(g.Groups) != 1 {
+		return "", errors.New("NOT requires exactly one filter or group")
+	}
+	var inner string
+	if len(g.Groups) == 1 {
+		sub, err := q.buildFilterGroup(g.Groups[0], args, aliasMap, depth+1, schema, aggs)
+		if err != nil {
+			return "", err
+		}
+		inner = sub
+	} else {
+		parts, err := q.collectFilters(g.Filters, args, aliasMap, schema, aggs)
+		if err != nil {
+			return "", err
+		}
+		inner = parts[0]
+	}
+	if inner == "" {
+		return "", nil
+	}
+	return "NOT (" + inner + ")", nil
+}
@@ -0,0 +1,95 @@
+package query_builder
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestEqInCombineWithRoot(t *testing.T) {
+	tests := []struct {
+		name string
+		q    *Query
+		want string
+		args []interface{}
+	}{
+		{"no root", New(PostgresDialect{}).From("users", "u").Eq("u.name", "x").In("u.id", []int{1, 2}),
+			`SELECT "u".* FROM "users" "u" WHERE "u"."name" = $1 AND "u"."id" IN ($2, $3)`, []interface{}{"x", 1, 2}},
+		{"and root", New(PostgresDialect{}).From("users", "u").Where(And(F("u.age", ">", 18))).Eq("u.name", "x"),
+			`SELECT "u".* FROM "users" "u" WHERE "u"."age" > $1 AND "u"."name" = $2`, []interface{}{18, "x"}},
+		{"or root", New(PostgresDialect{}).From("users", "u").Where(Or(F("u.age", ">", 18), F("u.admin", "=", true))).Eq("u.name", "x"),
+			`SELECT "u".* FROM "users" "u" WHERE "u"."name" = $1 AND ("u"."age" > $2 OR "u"."admin" = $3)`, []interface{}{"x", 18, true}},
+		{"not root", New(PostgresDialect{}).From("users", "u").Where(Not(F("u.name", "LIKE", "a%"))).Eq("u.name", "x").In("u.id", []int{1}),
+			`SELECT "u".* FROM "users" "u" WHERE "u"."name" = $1 AND "u"."id" IN ($2) AND (NOT ("u"."name" LIKE $3))`, []interface{}{"x", 1, "a%"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sql, args, err := tt.q.Build()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if sql != tt.want {
+				t.Errorf("sql =\n%s\nwant\n%s", sql, tt.want)
+			}
+			if !reflect.DeepEqual(args, tt.args) {
+				t.Errorf("args = %#v, want %#v", args, tt.args)
+			}
+		})
+	}
+}
+
+func TestNormalize(t *testing.T) {
+	render := func(g *FilterGroup) string {
+		sql, _, err := New(PostgresDialect{}).From("users", "u").Where(g).Build()
+		if err != nil {
+			t.Fatal(err)
+		}
+		return sql
+	}
+	const prefix = `SELECT "u".* FROM "users" "u" WHERE `
+	sub := New(PostgresDialect{}).From("orders", "o").Select("o.id")
+	tests := []struct {
+		name string
+		g    *FilterGroup
+		want string
+	}{
+		{"de morgan or", Not(Or(F("u.a", "=", 1), F("u.b", "<", 2))),
+			`"u"."a" != $1 AND "u"."b" >= $2`},
+		{"de morgan and", Not(And(F("u.a", "LIKE", "x%"), F("u.b", "IN", []int{1, 2}))),
+			`"u"."a" NOT LIKE $1 OR "u"."b" NOT IN ($2, $3)`},
+		{"double negation", Not(Not(F("u.a", ">", 1))),
+			`"u"."a" > $1`},
+		{"nested groups", Not(And(F("u.a", "IS NULL", nil), Or(F("u.b", "BETWEEN", []interface{}{1, 5}), Not(F("u.c", "<=", 3))))),
+			`"u"."a" IS NOT NULL OR ("u"."b" NOT BETWEEN $1 AND $2 AND ("u"."c" <= $3))`},
+		{"exists", Not(Filter{Op: "EXISTS", Value: sub}),
+			`NOT EXISTS (SELECT "o"."id" FROM "orders" "o")`},
+		{"lower case operator", Not(F("u.a", "not like", "x%")),
+			`"u"."a" LIKE $1`},
+		{"no negation", And(F("u.a", "=", 1), Or(F("u.b", "=", 2), F("u.c", "=", 3))),
+			`"u"."a" = $1 AND ("u"."b" = $2 OR "u"."c" = $3)`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			before := render(tt.g)
+			if got := render(tt.g.Normalize()); got != prefix+tt.want {
+				t.Errorf("normalized =\n%s\nwant\n%s", got, prefix+tt.want)
+			}
+			// Normalize returns a new tree and leaves its receiver alone.
+			if after := render(tt.g); after != before {
+				t.Errorf("Normalize changed its receiver:\n%s\nbecame\n%s", before, after)
+			}
+		})
+	}
+
+	// A filter whose operator has no opposite keeps an explicit NOT.
+	odd := F("u.a", "~", "x")
+	got := Not(Or(F("u.b", "=", 1), odd)).Normalize()
+	want := &FilterGroup{Operator: "AND", Filters: []Filter{{Column: Col("u.b"), Op: "!=", Value: 1, clientOp: "="}}, Groups: []FilterGroup{*Not(odd)}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Normalize = %+v, want %+v", got, want)
+	}
+
+	var nilGroup *FilterGroup
+	if nilGroup.Normalize() != nil {
+		t.Error("nil.Normalize() should be nil")
+	}
+}
@@ -0,0 +1,99 @@
+package query_builder
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// Operator arities, the number of values an operator binds.
+const (
+	arityNone = 0  // IS NULL and IS NOT NULL bind nothing
+	arityOne  = 1  // Comparisons and LIKE bind one value
+	arityTwo  = 2  // BETWEEN binds a lower and an upper bound
+	arityList = -1 // IN and NOT IN bind one value per list element
+)
+
+// Between constructs a BETWEEN filter with inclusive bounds.
+func Between(ref string, low, high interface{}) Filter {
+	return Filter{Column: Col(ref), Op: "BETWEEN", Value: []interface{}{low, high}}
+}
+
+// IsNull constructs an IS NULL filter.
+func IsNull(ref string) Filter {
+	return Filter{Column: Col(ref), Op: "IS NULL"}
+}
+
+// IsNotNull constructs an IS NOT NULL filter.
+func IsNotNull(ref string) Filter {
+	return Filter{Column: Col(ref), Op: "IS NOT NULL"}
+}
+
+// buildComparison renders "left op value" for an allowed, upper-cased operator,
+// binding as many values as the operator takes.
+func (q *Query) buildComparison(left, op string, val interface{}, args *[]interface{}) (string, error) {
+	bind := func(v interface{}) string {
+		*args = append(*args, v)
+		return q.dialect.Placeholder(len(*args))
+	}
+
+	switch allowedOperators[op] {
+	case arityNone:
+		if val != nil {
+			return "", fmt.Errorf("operator %s only compares with NULL", op)
+		}
+		switch op {
+		case "IS":
+			return left + " IS NULL", nil
+		case "IS NOT":
+			return left + " IS NOT NULL", nil
+		}
+		return fmt.Sprintf("%s %s", left, op), nil
+	case arityList:
+		return q.buildInList(left, op, val, args), nil
+	case arityTwo:
+		bounds, ok := betweenBounds(val)
+		if !ok {
+			return "", fmt.Errorf("operator %s requires two values", op)
+		}
+		return fmt.Sprintf("%s %s %s AND %s", left, op, bind(bounds[0]), bind(bounds[1])), nil
+	}
+
+	return q.renderBinary(left, op, bind(val)), nil
+}
+
+// renderBinary renders "left op right" for a single-value operator.
+//
+// Without a native ILIKE, both sides are compared in lower case instead.
+func (q *Query) renderBinary(left, op, right string) string {
+	if (op == "ILIKE" || op == "NOT ILIKE") && !q.caps().ILike {
+		return fmt.Sprintf("LOWER(%s) %s LOWER(%s)", left, strings.TrimSuffix(op, "ILIKE")+"LIKE", right)
+	}
+	return fmt.Sprintf("%s %s %s", left, op, right)
+}
+
+// betweenBounds returns the two elements of a BETWEEN value, which must be a
+// slice or array of length two.
+func betweenBounds(val interface{}) ([2]interface{}, bool) {
+	rv := reflect.ValueOf(val)
+	if !rv.IsValid() || (rv.Kind() != reflect.Slice && rv.Kind() != reflect.Array) || rv.Len() != 2 {
+		return [2]interface{}{}, false
+	}
+	if rv.Type().Elem().Kind() == reflect.Uint8 {
+		return [2]interface{}{}, false
+	}
+	return [2]interface{}{rv.Index(0).Interface(), rv.Index(1).Interface()}, true
+}
+
+// buildColumnComparison renders "left op right" where right is another column.
+//
+// Only operators that take a single value can compare columns.
+func (q *Query) buildColumnComparison(left, op string, right ColumnRef, aliasMap map[string]string, schema map[string]map[string]bool) (string, error) {
+	if allowedOperators[op] != arityOne {
+		return "", fmt.Errorf("operator %s cannot compare columns", op)
+	}
+	if err := q.validateCol(right, aliasMap, schema); err != nil {
+		return "", fmt.Errorf("invalid column: %v", err)
+	}
+	return q.renderBinary(left, op, q.quoteCol(right)), nil
+}
@@ -0,0 +1,72 @@
+package query_builder
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// whereTest is a single filter rendered into a WHERE clause.
+type whereTest struct {
+	name    string
+	d       Dialect
+	f       Filter
+	want    string // The WHERE clause
+	args    []interface{}
+	wantErr string
+}
+
+func TestOperators(t *testing.T) {
+	runWhereTests(t, []whereTest{
+		{"between", PostgresDialect{}, Between("u.age", 18, 65), `"u"."age" BETWEEN $1 AND $2`, []interface{}{18, 65}, ""},
+		{"not between array", PostgresDialect{}, F("u.age", "not between", [2]int{1, 2}), `"u"."age" NOT BETWEEN $1 AND $2`, []interface{}{1, 2}, ""},
+		{"between one value", PostgresDialect{}, F("u.age", "BETWEEN", []int{1}), "", nil, "operator BETWEEN requires two values"},
+		{"between bytes", PostgresDialect{}, F("u.age", "BETWEEN", []byte{1, 2}), "", nil, "operator BETWEEN requires two values"},
+		{"not like", PostgresDialect{}, F("u.name", "NOT LIKE", "a%"), `"u"."name" NOT LIKE $1`, []interface{}{"a%"}, ""},
+		{"native ilike", PostgresDialect{}, F("u.name", "ILIKE", "a%"), `"u"."name" ILIKE $1`, []interface{}{"a%"}, ""},
+		{"emulated ilike", MySQLDialect{}, F("u.name", "ILIKE", "a%"), "LOWER(`u`.`name`) LIKE LOWER(?)", []interface{}{"a%"}, ""},
+		{"emulated not ilike", OracleDialect{}, F("u.name", "NOT ILIKE", "a%"), `LOWER("u"."name") NOT LIKE LOWER(:1)`, []interface{}{"a%"}, ""},
+		{"is null", PostgresDialect{}, IsNull("u.email"), `"u"."email" IS NULL`, nil, ""},
+		{"is not null", PostgresDialect{}, IsNotNull("u.email"), `"u"."email" IS NOT NULL`, nil, ""},
+		{"is", PostgresDialect{}, F("u.email", "IS", nil), `"u"."email" IS NULL`, nil, ""},
+		{"is not", PostgresDialect{}, F("u.email", "is not", nil), `"u"."email" IS NOT NULL`, nil, ""},
+		{"is with value", PostgresDialect{}, F("u.email", "IS", "x"), "", nil, "operator IS only compares with NULL"},
+		{"unknown", PostgresDialect{}, F("u.email", "REGEXP", "x"), "", nil, "invalid operator: REGEXP"},
+	})
+}
+
+// runWhereTests builds each filter against a users table aliased u.
+func runWhereTests(t *testing.T, tests []whereTest) {
+	t.Helper()
+	schema := map[string]map[string]bool{"users": {"age": true, "name": true, "nick": true, "email": true, "id": true, "parent_id": true, "created_at": true, "updated_at": true}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sql, args, err := New(tt.d).WithSchema(schema).From("users", "u").Select("u.id").Where(And(tt.f)).Build()
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("Build error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			where := sql[strings.Index(sql, " WHERE ")+len(" WHERE "):]
+			if where != tt.want {
+				t.Errorf("WHERE %s, want %s", where, tt.want)
+			}
+			if !reflect.DeepEqual(args, tt.args) {
+				t.Errorf("args = %#v, want %#v", args, tt.args)
+			}
+		})
+	}
+}
+
+func TestColumnComparison(t *testing.T) {
+	runWhereTests(t, []whereTest{
+		{"less than", PostgresDialect{}, FC("u.created_at", "<", "u.updated_at"), `"u"."created_at" < "u"."updated_at"`, nil, ""},
+		{"emulated ilike", MySQLDialect{}, FC("u.name", "ILIKE", "u.nick"), "LOWER(`u`.`name`) LIKE LOWER(`u`.`nick`)", nil, ""},
+		{"list operator", PostgresDialect{}, FC("u.id", "IN", "u.parent_id"), "", nil, "operator IN cannot compare columns"},
+		{"unknown right column", PostgresDialect{}, FC("u.id", "=", "x.id"), "", nil, "invalid column: x.id"},
+	})
+}
@@ -0,0 +1,87 @@
+package query_builder
+
+import (
+	"fmt"
+	"math"
+	"strings"
+)
+
+// PaginationStyle describes the syntax a dialect uses to limit and skip rows.
+type PaginationStyle int
+
+const (
+	// PaginationLimitOffset renders LIMIT n OFFSET m; OFFSET may appear without LIMIT (Postgres).
+	PaginationLimitOffset PaginationStyle = iota
+	// PaginationLimitRequired renders LIMIT n OFFSET m, but OFFSET needs a LIMIT,
+	// so an offset alone is paired with the largest possible limit (MySQL).
+	PaginationLimitRequired
+	// PaginationOffsetFetch renders OFFSET m ROWS FETCH NEXT n ROWS ONLY (Oracle 12c and later).
+	PaginationOffsetFetch
+	// PaginationTop renders SELECT TOP (n) when there is no offset, and
+	// OFFSET m ROWS FETCH NEXT n ROWS ONLY otherwise. OFFSET requires ORDER BY,
+	// so ORDER BY (SELECT NULL) is added when the query has no sort, or ORDER BY
+	// the first output column for a set operation (SQL Server).
+	PaginationTop
+)
+
+// usesTop reports whether the query's limit is rendered as SELECT TOP (n).
+func (q *Query) usesTop() bool {
+	return q.caps().Pagination == PaginationTop && q.limit > 0 && q.pageOffset() <= 0
+}
+
+// buildTop renders "TOP (n) " after SELECT when the dialect paginates that way.
+func (q *Query) buildTop(sb *strings.Builder, args *[]interface{}) {
+	if !q.usesTop() {
+		return
+	}
+	*args = append(*args, q.limit)
+	sb.WriteString(fmt.Sprintf("TOP (%s) ", q.dialect.Placeholder(len(*args))))
+}
+
+// writeLimitOffset renders limit and offset in the dialect's pagination style.
+//
+// A zero limit or offset is omitted; both are bound as parameters. sorted
+// reports whether an ORDER BY has already been written.
+func (q *Query) writeLimitOffset(sb *strings.Builder, args *[]interface{}, limit, offset int, sorted bool) {
+	if limit <= 0 && offset <= 0 {
+		return
+	}
+	bind := func(v interface{}) string {
+		*args = append(*args, v)
+		return q.dialect.Placeholder(len(*args))
+	}
+
+	switch q.caps().Pagination {
+	case PaginationTop:
+		if !sorted {
+			sb.WriteString(" ORDER BY (SELECT NULL)")
+		}
+		sb.WriteString(fmt.Sprintf(" OFFSET %s ROWS", bind(offset)))
+		if limit > 0 {
+			sb.WriteString(fmt.Sprintf(" FETCH NEXT %s ROWS ONLY", bind(limit)))
+		}
+	case PaginationOffsetFetch:
+		if offset > 0 {
+			sb.WriteString(fmt.Sprintf(" OFFSET %s ROWS", bind(offset)))
+		}
+		if limit > 0 {
+			sb.WriteString(fmt.Sprintf(" FETCH NEXT %s ROWS ONLY", bind(limit)))
+		}
+	case PaginationLimitRequired:
+		if limit > 0 {
+			sb.WriteString(fmt.Sprintf(" LIMIT %s", bind(limit)))
+		} else {
+			sb.WriteString(fmt.Sprintf(" LIMIT %s", bind(int64(math.MaxInt64))))
+		}
+		if offset > 0 {
+			sb.WriteString(fmt.Sprintf(" OFFSET %s", bind(offset)))
+		}
+	default:
+		if limit > 0 {
+			sb.WriteString(fmt.Sprintf(" LIMIT %s", bind(limit)))
+		}
+		if offset > 0 {
+			sb.WriteString(fmt.Sprintf(" OFFSET %s", bind(offset)))
+		}
+	}
+}
@@ -0,0 +1,69 @@
+package query_builder
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"testing"
+)
+
+// plainDialect implements only Dialect, so it gets the zero Capabilities.
+type plainDialect struct{}
+
+func (plainDialect) Placeholder(index int) string       { return fmt.Sprintf("$%d", index) }
+func (plainDialect) QuoteIdentifier(name string) string { return name }
+
+func TestPaginationStyles(t *testing.T) {
+	page := func(d Dialect, limit, offset int, sorted bool) *Query {
+		q := New(d).From("users", "u").Select("u.id").Limit(limit).Offset(offset)
+		if sorted {
+			q.OrderBy("u.id", "ASC")
+		}
+		return q
+	}
+	tests := []struct {
+		name string
+		q    *Query
+		want string
+		args []interface{}
+	}{
+		// PaginationLimitOffset
+		{"postgres limit", page(PostgresDialect{}, 10, 0, false), `SELECT "u"."id" FROM "users" "u" LIMIT $1`, []interface{}{10}},
+		{"postgres offset only", page(PostgresDialect{}, 0, 20, false), `SELECT "u"."id" FROM "users" "u" OFFSET $1`, []interface{}{20}},
+		{"postgres both", page(PostgresDialect{}, 10, 20, true), `SELECT "u"."id" FROM "users" "u" ORDER BY "u"."id" ASC LIMIT $1 OFFSET $2`, []interface{}{10, 20}},
+		{"zero capabilities", page(plainDialect{}, 10, 20, false), `SELECT u.id FROM users u LIMIT $1 OFFSET $2`, []interface{}{10, 20}},
+
+		// PaginationLimitRequired
+		{"mysql both", page(MySQLDialect{}, 10, 20, false), "SELECT `u`.`id` FROM `users` `u` LIMIT ? OFFSET ?", []interface{}{10, 20}},
+		{"mysql offset only", page(MySQLDialect{}, 0, 20, false), "SELECT `u`.`id` FROM `users` `u` LIMIT ? OFFSET ?", []interface{}{int64(math.MaxInt64), 20}},
+		{"sqlite offset only", page(SQLiteDialect{}, 0, 5, false), `SELECT "u"."id" FROM "users" "u" LIMIT ? OFFSET ?`, []interface{}{int64(math.MaxInt64), 5}},
+
+		// PaginationOffsetFetch
+		{"oracle limit", page(OracleDialect{}, 10, 0, false), `SELECT "u"."id" FROM "users" "u" FETCH NEXT :1 ROWS ONLY`, []interface{}{10}},
+		{"oracle offset only", page(OracleDialect{}, 0, 20, false), `SELECT "u"."id" FROM "users" "u" OFFSET :1 ROWS`, []interface{}{20}},
+		{"oracle both", page(OracleDialect{}, 10, 20, true), `SELECT "u"."id" FROM "users" "u" ORDER BY "u"."id" ASC OFFSET :1 ROWS FETCH NEXT :2 ROWS ONLY`, []interface{}{20, 10}},
+
+		// PaginationTop
+		{"sqlserver top", page(SQLServerDialect{}, 10, 0, true), `SELECT TOP (@p1) [u].[id] FROM [users] [u] ORDER BY [u].[id] ASC`, []interface{}{10}},
+		{"sqlserver offset only", page(SQLServerDialect{}, 0, 20, false), `SELECT [u].[id] FROM [users] [u] ORDER BY (SELECT NULL) OFFSET @p1 ROWS`, []interface{}{20}},
+		{"sqlserver both sorted", page(SQLServerDialect{}, 10, 20, true), `SELECT [u].[id] FROM [users] [u] ORDER BY [u].[id] ASC OFFSET @p1 ROWS FETCH NEXT @p2 ROWS ONLY`, []interface{}{20, 10}},
+		{"sqlserver keyset ignores offset", page(SQLServerDialect{}, 10, 20, true).KeysetPagination(map[string]interface{}{"u.id": 3}),
+			`SELECT TOP (@p1) [u].[id] FROM [users] [u] WHERE [u].[id] > @p2 ORDER BY [u].[id] ASC`, []interface{}{10, 3}},
+
+		{"none", page(OracleDialect{}, 0, 0, false), `SELECT "u"."id" FROM "users" "u"`, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sql, args, err := tt.q.Build()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if sql != tt.want {
+				t.Errorf("sql =\n%s\nwant\n%s", sql, tt.want)
+			}
+			if !reflect.DeepEqual(args, tt.args) {
+				t.Errorf("args = %#v, want %#v", args, tt.args)
+			}
+		})
+	}
+}
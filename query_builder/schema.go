@@ -0,0 +1,301 @@
+package query_builder
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"time"
+)
+
+// ColumnType is the declared type of a column in a Schema.
+type ColumnType string
+
+// Column types understood by Schema. TypeAny disables value checking.
+const (
+	TypeAny       ColumnType = ""
+	TypeInt       ColumnType = "int"
+	TypeFloat     ColumnType = "float"
+	TypeText      ColumnType = "text"
+	TypeTimestamp ColumnType = "timestamp"
+	TypeBool      ColumnType = "bool"
+	TypeUUID      ColumnType = "uuid"
+	TypeJSON      ColumnType = "json"
+	TypeEnum      ColumnType = "enum"
+)
+
+// knownColumnTypes lists every ColumnType constant.
+var knownColumnTypes = map[ColumnType]bool{
+	TypeAny: true, TypeInt: true, TypeFloat: true, TypeText: true, TypeTimestamp: true,
+	TypeBool: true, TypeUUID: true, TypeJSON: true, TypeEnum: true,
+}
+
+// Column describes a single column of a typed Schema.
+type Column struct {
+	Type     ColumnType // Declared type; values are checked or coerced against it
+	Nullable bool       // If true, NULL may be written to the column
+	Unique   bool       // If true, the column holds unique values (used by keyset pagination)
+	Values   []string   // Allowed values for TypeEnum; empty allows any string
+}
+
+// Table maps column names to their definitions.
+type Table map[string]Column
+
+// Schema describes tables and their typed columns: map[table]map[column]Column.
+type Schema map[string]Table
+
+// WithTypedSchema sets a typed validation schema.
+//
+// Table and column references are validated as with WithSchema. In addition,
+// filter values and written values are checked against the column types:
+// values that convert losslessly, such as the string "18" for an int column,
+// are coerced, and anything else is rejected before it reaches the driver.
+// Columns marked Unique count as unique for keyset pagination.
+func (q *Query) WithTypedSchema(schema Schema) *Query {
+	q.typedSchema = schema
+	q.allowedSchema = schema.AllowList()
+	return q
+}
+
+// AllowList returns the schema in the map[table][column]bool form used by WithSchema.
+func (s Schema) AllowList() map[string]map[string]bool {
+	if s == nil {
+		return nil
+	}
+	out := make(map[string]map[string]bool, len(s))
+	for table, cols := range s {
+		out[table] = make(map[string]bool, len(cols))
+		for name := range cols {
+			out[table][name] = true
+		}
+	}
+	return out
+}
+
+// typedColumn looks up the definition of ref, if the typed schema declares it.
+func (q *Query) typedColumn(ref ColumnRef, aliasMap map[string]string) (Column, bool) {
+	table, ok := aliasMap[ref.TableAlias]
+	if !ok {
+		return Column{}, false
+	}
+	col, ok := q.typedSchema[table][ref.ColumnName]
+	return col, ok
+}
+
+// coerceFilterValue checks f's value against the type of its column and
+// returns the value to bind.
+func (q *Query) coerceFilterValue(f Filter, op string, aliasMap map[string]string) (interface{}, error) {
+	col, ok := q.typedColumn(f.Column, aliasMap)
+	if !ok {
+		return f.Value, nil
+	}
+	name := f.Column.TableAlias + "." + f.Column.ColumnName
+
+	switch allowedOperators[op] {
+	case arityNone:
+		return f.Value, nil
+	case arityTwo:
+		bounds, ok := betweenBounds(f.Value)
+		if !ok {
+			return f.Value, nil
+		}
+		low, err := col.coerce(bounds[0], name)
+		if err != nil {
+			return nil, err
+		}
+		high, err := col.coerce(bounds[1], name)
+		if err != nil {
+			return nil, err
+		}
+		return []interface{}{low, high}, nil
+	case arityList:
+		rv := reflect.ValueOf(f.Value)
+		if !rv.IsValid() || (rv.Kind() != reflect.Slice && rv.Kind() != reflect.Array) || rv.Type().Elem().Kind() == reflect.Uint8 {
+			return col.coerce(f.Value, name)
+		}
+		// Keep the original slice unless an element changes, so array
+		// parameters still see the caller's slice type.
+		vals := make([]interface{}, rv.Len())
+		changed := false
+		for n := range vals {
+			elem := rv.Index(n).Interface()
+			v, err := col.coerce(elem, name)
+			if err != nil {
+				return nil, err
+			}
+			vals[n] = v
+			if !reflect.DeepEqual(v, elem) {
+				changed = true
+			}
+		}
+		if !changed {
+			return f.Value, nil
+		}
+		return vals, nil
+	}
+
+	switch op {
+	case "LIKE", "NOT LIKE", "ILIKE", "NOT ILIKE":
+		if _, ok := f.Value.(string); !ok {
+			return nil, &valueError{name: name, reason: fmt.Sprintf("%s pattern must be a string, got %T", op, f.Value)}
+		}
+		return f.Value, nil
+	}
+	if f.Value == nil {
+		return nil, &valueError{name: name, reason: fmt.Sprintf("nil never matches %s, use IS NULL", op)}
+	}
+	return col.coerce(f.Value, name)
+}
+
+// coerceWrite checks a value written to table.column by INSERT or UPDATE.
+func (q *Query) coerceWrite(table, column string, val interface{}) (interface{}, error) {
+	col, ok := q.typedSchema[table][column]
+	if !ok {
+		return val, nil
+	}
+	if val == nil && !col.Nullable {
+		return nil, &valueError{name: table + "." + column, reason: "column is not nullable"}
+	}
+	return col.coerce(val, table+"."+column)
+}
+
+// coerce converts val to the column's type, or returns an error naming the column.
+//
+// nil and driver.Valuer values are passed through unchanged.
+func (c Column) coerce(val interface{}, name string) (interface{}, error) {
+	if val == nil || c.Type == TypeAny {
+		return val, nil
+	}
+	if _, ok := val.(driver.Valuer); ok {
+		return val, nil
+	}
+	v, err := coerceValue(c, val)
+	if err != nil {
+		return nil, &valueError{name: name, reason: err.Error()}
+	}
+	return v, nil
+}
+
+// valueError reports a value that does not fit the type of its column.
+type valueError struct {
+	name   string // Column, or public field, the value was meant for
+	reason string // What is wrong with the value, e.g. "expected int, got string"
+}
+
+// Error names the column and the reason.
+func (e *valueError) Error() string {
+	return fmt.Sprintf("invalid value for %s: %s", e.name, e.reason)
+}
+
+// coerceValue implements Column.coerce for a non-nil value.
+func coerceValue(c Column, val interface{}) (interface{}, error) {
+	rv := reflect.ValueOf(val)
+	mismatch := fmt.Errorf("expected %s, got %T", c.Type, val)
+
+	switch c.Type {
+	case TypeInt:
+		switch rv.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+			reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			return val, nil
+		case reflect.Float32, reflect.Float64:
+			if f := rv.Float(); f == math.Trunc(f) && math.Abs(f) < 1<<63 {
+				return int64(f), nil
+			}
+		case reflect.String:
+			if n, err := strconv.ParseInt(rv.String(), 10, 64); err == nil {
+				return n, nil
+			}
+		}
+	case TypeFloat:
+		switch rv.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+			reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+			reflect.Float32, reflect.Float64:
+			return val, nil
+		case reflect.String:
+			if f, err := strconv.ParseFloat(rv.String(), 64); err == nil {
+				return f, nil
+			}
+		}
+	case TypeText:
+		if rv.Kind() == reflect.String {
+			return val, nil
+		}
+	case TypeBool:
+		switch rv.Kind() {
+		case reflect.Bool:
+			return val, nil
+		case reflect.String:
+			if b, err := strconv.ParseBool(rv.String()); err == nil {
+				return b, nil
+			}
+		}
+	case TypeTimestamp:
+		switch v := val.(type) {
+		case time.Time:
+			return v, nil
+		case string:
+			for _, layout := range []string{time.RFC3339Nano, "2006-01-02 15:04:05", "2006-01-02"} {
+				if t, err := time.Parse(layout, v); err == nil {
+					return t, nil
+				}
+			}
+		}
+	case TypeUUID:
+		switch {
+		case rv.Kind() == reflect.String && isUUID(rv.String()):
+			return val, nil
+		case (rv.Kind() == reflect.Array || rv.Kind() == reflect.Slice) && rv.Type().Elem().Kind() == reflect.Uint8 && rv.Len() == 16:
+			return val, nil
+		}
+	case TypeJSON:
+		switch val.(type) {
+		case string, []byte, json.RawMessage:
+			return val, nil
+		}
+		b, err := json.Marshal(val)
+		if err != nil {
+			return nil, fmt.Errorf("expected json: %v", err)
+		}
+		return string(b), nil
+	case TypeEnum:
+		if rv.Kind() != reflect.String {
+			break
+		}
+		if len(c.Values) == 0 {
+			return val, nil
+		}
+		for _, allowed := range c.Values {
+			if rv.String() == allowed {
+				return val, nil
+			}
+		}
+		return nil, fmt.Errorf("value %q is not one of the enum values", rv.String())
+	default:
+		return nil, fmt.Errorf("unknown column type: %s", c.Type)
+	}
+	return nil, mismatch
+}
+
+// isUUID reports whether s is a UUID in canonical 8-4-4-4-12 hex form.
+func isUUID(s string) bool {
+	if len(s) != 36 {
+		return false
+	}
+	for n, r := range s {
+		switch n {
+		case 8, 13, 18, 23:
+			if r != '-' {
+				return false
+			}
+		default:
+			if !(r >= '0' && r <= '9' || r >= 'a' && r <= 'f' || r >= 'A' && r <= 'F') {
+				return false
+			}
+		}
+	}
+	return true
+}
@@ -0,0 +1,135 @@
+package query_builder
+
+import (
+	"database/sql"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestColumnCoerce(t *testing.T) {
+	day := time.Date(2026, 10, 16, 0, 0, 0, 0, time.UTC)
+	uuid := "123e4567-e89b-12d3-a456-426614174000"
+	status := Column{Type: TypeEnum, Values: []string{"active", "disabled"}}
+	tests := []struct {
+		col     Column
+		in      interface{}
+		want    interface{}
+		wantErr string
+	}{
+		{Column{Type: TypeInt}, 42, 42, ""},
+		{Column{Type: TypeInt}, uint8(7), uint8(7), ""},
+		{Column{Type: TypeInt}, "18", int64(18), ""},
+		{Column{Type: TypeInt}, 3.0, int64(3), ""},
+		{Column{Type: TypeInt}, 3.5, nil, "expected int, got float64"},
+		{Column{Type: TypeInt}, 1e19, nil, "expected int, got float64"},
+		{Column{Type: TypeInt}, "18.5", nil, "expected int, got string"},
+		{Column{Type: TypeInt}, true, nil, "expected int, got bool"},
+		{Column{Type: TypeFloat}, 2, 2, ""},
+		{Column{Type: TypeFloat}, "2.5", 2.5, ""},
+		{Column{Type: TypeFloat}, "abc", nil, "expected float, got string"},
+		{Column{Type: TypeText}, "x", "x", ""},
+		{Column{Type: TypeText}, 5, nil, "expected text, got int"},
+		{Column{Type: TypeBool}, false, false, ""},
+		{Column{Type: TypeBool}, "true", true, ""},
+		{Column{Type: TypeBool}, "yes", nil, "expected bool, got string"},
+		{Column{Type: TypeBool}, 1, nil, "expected bool, got int"},
+		{Column{Type: TypeTimestamp}, day, day, ""},
+		{Column{Type: TypeTimestamp}, "2026-10-16", day, ""},
+		{Column{Type: TypeTimestamp}, "2026-10-16 00:00:00", day, ""},
+		{Column{Type: TypeTimestamp}, "2026-10-16T00:00:00Z", day, ""},
+		{Column{Type: TypeTimestamp}, "16/10/2026", nil, "expected timestamp, got string"},
+		{Column{Type: TypeTimestamp}, 1760572800, nil, "expected timestamp, got int"},
+		{Column{Type: TypeUUID}, uuid, uuid, ""},
+		{Column{Type: TypeUUID}, [16]byte{1}, [16]byte{1}, ""},
+		{Column{Type: TypeUUID}, "123e4567e89b12d3a456426614174000", nil, "expected uuid, got string"},
+		{Column{Type: TypeUUID}, []byte{1, 2}, nil, "expected uuid, got []uint8"},
+		{Column{Type: TypeJSON}, `{"a":1}`, `{"a":1}`, ""},
+		{Column{Type: TypeJSON}, json.RawMessage(`[1]`), json.RawMessage(`[1]`), ""},
+		{Column{Type: TypeJSON}, map[string]int{"a": 1}, `{"a":1}`, ""},
+		{Column{Type: TypeJSON}, make(chan int), nil, "expected json"},
+		{status, "active", "active", ""},
+		{status, "deleted", nil, `value "deleted" is not one of the enum values`},
+		{status, 1, nil, "expected enum, got int"},
+		{Column{Type: TypeEnum}, "anything", "anything", ""},
+		{Column{}, struct{}{}, struct{}{}, ""},
+		{Column{Type: TypeInt}, nil, nil, ""},
+		{Column{Type: TypeInt}, sql.NullString{String: "x", Valid: true}, sql.NullString{String: "x", Valid: true}, ""},
+		{Column{Type: "money"}, 1, nil, "unknown column type: money"},
+	}
+	for _, tt := range tests {
+		got, err := tt.col.coerce(tt.in, "t.c")
+		if tt.wantErr != "" {
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) || !strings.HasPrefix(err.Error(), "invalid value for t.c: ") {
+				t.Errorf("%s coerce(%#v) error = %v, want %q", tt.col.Type, tt.in, err, tt.wantErr)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s coerce(%#v): %v", tt.col.Type, tt.in, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s coerce(%#v) = %#v, want %#v", tt.col.Type, tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestTypedSchemaValues(t *testing.T) {
+	schema := Schema{"users": {
+		"id":    {Type: TypeInt, Unique: true},
+		"name":  {Type: TypeText},
+		"email": {Type: TypeText, Nullable: true},
+	}}
+	typed := func() *Query { return New(PostgresDialect{}).WithTypedSchema(schema) }
+	tests := []struct {
+		name    string
+		build   func() (string, []interface{}, error)
+		args    []interface{}
+		wantErr string
+	}{
+		{"filter coerced", typed().From("users", "u").Where(And(F("u.id", ">", "18"))).Build,
+			[]interface{}{int64(18)}, ""},
+		{"in list coerced", typed().From("users", "u").In("u.id", []string{"1", "2"}).Build,
+			[]interface{}{int64(1), int64(2)}, ""},
+		{"between coerced", typed().From("users", "u").Where(And(F("u.id", "BETWEEN", []interface{}{"1", 5}))).Build,
+			[]interface{}{int64(1), 5}, ""},
+		{"filter rejected", typed().From("users", "u").Eq("u.id", "abc").Build,
+			nil, "invalid value for u.id: expected int, got string"},
+		{"like needs a string", typed().From("users", "u").Where(And(F("u.name", "LIKE", 5))).Build,
+			nil, "LIKE pattern must be a string, got int"},
+		{"nil comparison", typed().From("users", "u").Eq("u.name", nil).Build,
+			nil, "nil never matches =, use IS NULL"},
+		{"is null untouched", typed().From("users", "u").Where(And(F("u.name", "IS NULL", nil))).Build,
+			nil, ""},
+		{"insert coerced", typed().Insert("users").Columns("id", "name", "email").Values("7", "ann", nil).Build,
+			[]interface{}{int64(7), "ann", nil}, ""},
+		{"insert null into not null", typed().Insert("users").Columns("id", "name").Values(1, nil).Build,
+			nil, "invalid value for users.name: column is not nullable"},
+		{"update null into nullable", typed().Update("users", "u").Set("email", nil).Where(And(F("u.id", "=", 1))).Build,
+			[]interface{}{nil, 1}, ""},
+		{"update null into not null", typed().Update("users", "u").Set("name", nil).Where(And(F("u.id", "=", 1))).Build,
+			nil, "invalid value for users.name: column is not nullable"},
+		{"update rejected", typed().Update("users", "u").Set("id", "x").Where(And(F("u.id", "=", 1))).Build,
+			nil, "invalid value for users.id: expected int, got string"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, args, err := tt.build()
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("Build error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(args, tt.args) {
+				t.Errorf("args = %#v, want %#v", args, tt.args)
+			}
+		})
+	}
+}
@@ -0,0 +1,350 @@
+package query_builder
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// SchemaFormat selects the file format used by ParseSchema and MarshalSchema.
+type SchemaFormat int
+
+const (
+	// SchemaJSON is the JSON schema format.
+	SchemaJSON SchemaFormat = iota
+	// SchemaYAML is the YAML schema format.
+	SchemaYAML
+)
+
+// ParseSchema decodes a schema file.
+//
+// The file holds a "tables" object mapping each table to its columns. A
+// column is either true (an untyped, allow-list only column), a type name,
+// or an object with type, nullable, unique and values keys:
+//
+//	tables:
+//	  users:
+//	    id: {type: int, unique: true}
+//	    name: text
+//	    email: {type: text, nullable: true}
+//	    status: {type: enum, values: [active, disabled]}
+//	    notes: true
+//
+// Errors for malformed files include the line they were found on. Use
+// Schema.AllowList to get the map accepted by WithSchema.
+func ParseSchema(data []byte, format SchemaFormat) (Schema, error) {
+	switch format {
+	case SchemaJSON:
+		return parseJSONSchema(data)
+	case SchemaYAML:
+		return parseYAMLSchema(data)
+	}
+	return nil, fmt.Errorf("unknown schema format: %d", format)
+}
+
+// LoadSchemaFile reads and parses a schema file, choosing the format from its
+// extension: .json, or .yaml and .yml.
+func LoadSchemaFile(path string) (Schema, error) {
+	format, err := schemaFileFormat(path)
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	schema, err := ParseSchema(data, format)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+	return schema, nil
+}
+
+// MarshalSchema encodes s in the given format, so that ParseSchema returns an
+// equal Schema. Tables and columns are written in sorted order.
+func MarshalSchema(s Schema, format SchemaFormat) ([]byte, error) {
+	tables := make(map[string]map[string]interface{}, len(s))
+	for name, cols := range s {
+		tables[name] = make(map[string]interface{}, len(cols))
+		for col, def := range cols {
+			tables[name][col] = columnDocument(def)
+		}
+	}
+	doc := map[string]interface{}{"tables": tables}
+
+	switch format {
+	case SchemaJSON:
+		data, err := json.MarshalIndent(doc, "", "  ")
+		if err != nil {
+			return nil, err
+		}
+		return append(data, '\n'), nil
+	case SchemaYAML:
+		return yaml.Marshal(doc)
+	}
+	return nil, fmt.Errorf("unknown schema format: %d", format)
+}
+
+// SchemaFromAllowList converts a WithSchema map into an untyped Schema, for
+// example to write it out with MarshalSchema. Columns mapped to false are dropped.
+func SchemaFromAllowList(allowed map[string]map[string]bool) Schema {
+	s := make(Schema, len(allowed))
+	for table, cols := range allowed {
+		s[table] = make(Table, len(cols))
+		for name, ok := range cols {
+			if ok {
+				s[table][name] = Column{}
+			}
+		}
+	}
+	return s
+}
+
+// schemaFileFormat picks the format for path from its extension.
+func schemaFileFormat(path string) (SchemaFormat, error) {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+		return SchemaJSON, nil
+	case ".yaml", ".yml":
+		return SchemaYAML, nil
+	}
+	return 0, fmt.Errorf("unknown schema file extension: %s", path)
+}
+
+// columnDocument returns the shortest file form of a column.
+func columnDocument(c Column) interface{} {
+	if !c.Nullable && !c.Unique && len(c.Values) == 0 {
+		if c.Type == TypeAny {
+			return true
+		}
+		return string(c.Type)
+	}
+	doc := make(map[string]interface{})
+	if c.Type != TypeAny {
+		doc["type"] = string(c.Type)
+	}
+	if c.Nullable {
+		doc["nullable"] = true
+	}
+	if c.Unique {
+		doc["unique"] = true
+	}
+	if len(c.Values) > 0 {
+		doc["values"] = c.Values
+	}
+	return doc
+}
+
+// columnFromDocument converts a decoded column entry into a Column. ok is false
+// for a column mapped to false, which is left out of the schema.
+func columnFromDocument(v interface{}) (col Column, ok bool, err error) {
+	switch val := v.(type) {
+	case bool:
+		return Column{}, val, nil
+	case string:
+		if !knownColumnTypes[ColumnType(val)] {
+			return Column{}, false, fmt.Errorf("unknown column type: %s", val)
+		}
+		return Column{Type: ColumnType(val)}, true, nil
+	case map[string]interface{}:
+		for key, field := range val {
+			switch key {
+			case "type":
+				t, isStr := field.(string)
+				if !isStr || !knownColumnTypes[ColumnType(t)] {
+					return Column{}, false, fmt.Errorf("unknown column type: %v", field)
+				}
+				col.Type = ColumnType(t)
+			case "nullable", "unique":
+				b, isBool := field.(bool)
+				if !isBool {
+					return Column{}, false, fmt.Errorf("%s must be true or false", key)
+				}
+				if key == "nullable" {
+					col.Nullable = b
+				} else {
+					col.Unique = b
+				}
+			case "values":
+				list, isList := field.([]interface{})
+				if !isList {
+					return Column{}, false, errors.New("values must be a list of strings")
+				}
+				for _, item := range list {
+					s, isStr := item.(string)
+					if !isStr {
+						return Column{}, false, errors.New("values must be a list of strings")
+					}
+					col.Values = append(col.Values, s)
+				}
+			default:
+				return Column{}, false, fmt.Errorf("unknown column key: %s", key)
+			}
+		}
+		if len(col.Values) > 0 && col.Type != TypeEnum {
+			return Column{}, false, errors.New("values require type enum")
+		}
+		return col, true, nil
+	}
+	return Column{}, false, errors.New("column must be true, a type name, or an object")
+}
+
+// parseJSONSchema decodes the JSON format, tracking lines for error messages.
+func parseJSONSchema(data []byte) (Schema, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	line := func() int {
+		return 1 + bytes.Count(data[:dec.InputOffset()], []byte("\n"))
+	}
+	fail := func(err error) error {
+		var syntax *json.SyntaxError
+		if errors.As(err, &syntax) {
+			return fmt.Errorf("line %d: %v", 1+bytes.Count(data[:syntax.Offset], []byte("\n")), err)
+		}
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		return fmt.Errorf("line %d: %v", line(), err)
+	}
+	// object reads the members of a JSON object, calling member with each key.
+	object := func(what string, member func(key string) error) error {
+		tok, err := dec.Token()
+		if err != nil {
+			return fail(err)
+		}
+		if d, ok := tok.(json.Delim); !ok || d != '{' {
+			return fmt.Errorf("line %d: %s must be an object", line(), what)
+		}
+		for dec.More() {
+			tok, err := dec.Token()
+			if err != nil {
+				return fail(err)
+			}
+			if err := member(tok.(string)); err != nil {
+				return err
+			}
+		}
+		if _, err := dec.Token(); err != nil {
+			return fail(err)
+		}
+		return nil
+	}
+
+	var schema Schema
+	err := object("schema", func(key string) error {
+		if key != "tables" {
+			return fmt.Errorf("line %d: unknown key: %s", line(), key)
+		}
+		if schema != nil {
+			return fmt.Errorf("line %d: duplicate key: tables", line())
+		}
+		schema = make(Schema)
+		return object("tables", func(table string) error {
+			if _, exists := schema[table]; exists {
+				return fmt.Errorf("line %d: duplicate table: %s", line(), table)
+			}
+			schema[table] = make(Table)
+			return object("table "+table, func(name string) error {
+				at := line()
+				if _, exists := schema[table][name]; exists {
+					return fmt.Errorf("line %d: duplicate column: %s.%s", at, table, name)
+				}
+				var v interface{}
+				if err := dec.Decode(&v); err != nil {
+					return fail(err)
+				}
+				col, ok, err := columnFromDocument(v)
+				if err != nil {
+					return fmt.Errorf("line %d: column %s.%s: %v", at, table, name, err)
+				}
+				if ok {
+					schema[table][name] = col
+				}
+				return nil
+			})
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	if _, err := dec.Token(); err != io.EOF {
+		return nil, fmt.Errorf("line %d: unexpected data after schema", line())
+	}
+	if schema == nil {
+		return nil, errors.New("line 1: tables required")
+	}
+	return schema, nil
+}
+
+// parseYAMLSchema decodes the YAML format using node positions for error messages.
+func parseYAMLSchema(data []byte) (Schema, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+	if doc.Kind != yaml.DocumentNode || len(doc.Content) == 0 {
+		return nil, errors.New("line 1: tables required")
+	}
+	root := doc.Content[0]
+	if root.Kind != yaml.MappingNode {
+		return nil, fmt.Errorf("line %d: schema must be a mapping", root.Line)
+	}
+
+	var schema Schema
+	for n := 0; n < len(root.Content); n += 2 {
+		key, tables := root.Content[n], root.Content[n+1]
+		if key.Value != "tables" {
+			return nil, fmt.Errorf("line %d: unknown key: %s", key.Line, key.Value)
+		}
+		if schema != nil {
+			return nil, fmt.Errorf("line %d: duplicate key: tables", key.Line)
+		}
+		if tables.Kind != yaml.MappingNode {
+			return nil, fmt.Errorf("line %d: tables must be a mapping", tables.Line)
+		}
+		schema = make(Schema)
+		for t := 0; t < len(tables.Content); t += 2 {
+			tkey, cols := tables.Content[t], tables.Content[t+1]
+			table := tkey.Value
+			if _, exists := schema[table]; exists {
+				return nil, fmt.Errorf("line %d: duplicate table: %s", tkey.Line, table)
+			}
+			schema[table] = make(Table)
+			// An empty table may be written as "users:" with no columns.
+			if cols.Kind == yaml.ScalarNode && cols.Tag == "!!null" {
+				continue
+			}
+			if cols.Kind != yaml.MappingNode {
+				return nil, fmt.Errorf("line %d: table %s must be a mapping", cols.Line, table)
+			}
+			for c := 0; c < len(cols.Content); c += 2 {
+				ckey, def := cols.Content[c], cols.Content[c+1]
+				name := ckey.Value
+				if _, exists := schema[table][name]; exists {
+					return nil, fmt.Errorf("line %d: duplicate column: %s.%s", ckey.Line, table, name)
+				}
+				var v interface{}
+				if err := def.Decode(&v); err != nil {
+					return nil, fmt.Errorf("line %d: column %s.%s: %v", ckey.Line, table, name, err)
+				}
+				col, ok, err := columnFromDocument(v)
+				if err != nil {
+					return nil, fmt.Errorf("line %d: column %s.%s: %v", ckey.Line, table, name, err)
+				}
+				if ok {
+					schema[table][name] = col
+				}
+			}
+		}
+	}
+	if schema == nil {
+		return nil, fmt.Errorf("line %d: tables required", root.Line)
+	}
+	return schema, nil
+}
@@ -0,0 +1,136 @@
+package query_builder
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseSchemaErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		format SchemaFormat
+		data   string
+		want   string
+	}{
+		{"json unknown type", SchemaJSON, "{\n  \"tables\": {\n    \"users\": {\n      \"id\": \"integer\"\n    }\n  }\n}", "line 4: column users.id: unknown column type: integer"},
+		{"json duplicate column", SchemaJSON, "{\n  \"tables\": {\n    \"users\": {\n      \"id\": \"int\",\n      \"id\": \"text\"\n    }\n  }\n}", "line 5: duplicate column: users.id"},
+		{"json duplicate table", SchemaJSON, "{\"tables\": {\n  \"users\": {},\n  \"users\": {}\n}}", "line 3: duplicate table: users"},
+		{"json duplicate tables", SchemaJSON, "{\"tables\": {},\n \"tables\": {}}", "line 2: duplicate key: tables"},
+		{"json values without enum", SchemaJSON, "{\"tables\": {\"users\": {\n  \"s\": {\"type\": \"text\", \"values\": [\"a\"]}\n}}}", "line 2: column users.s: values require type enum"},
+		{"json syntax", SchemaJSON, "{\"tables\": {\"users\": {\n  \"id\": \"int\",\n}}}", "line 2: invalid character ','"},
+		{"json truncated", SchemaJSON, "{\"tables\": {\n  \"users\": {\n", "line 3: unexpected end of JSON input"},
+		{"json trailing data", SchemaJSON, "{\"tables\": {}}\n{}", "line 2: unexpected data after schema"},
+		{"json unknown key", SchemaJSON, "{\n  \"other\": {}\n}", "line 2: unknown key: other"},
+		{"json missing tables", SchemaJSON, "{}", "line 1: tables required"},
+		{"yaml unknown type", SchemaYAML, "tables:\n  users:\n    id: integer\n", "line 3: column users.id: unknown column type: integer"},
+		{"yaml duplicate column", SchemaYAML, "tables:\n  users:\n    id: int\n    id: text\n", "line 4: duplicate column: users.id"},
+		{"yaml duplicate table", SchemaYAML, "tables:\n  users: {}\n  users: {}\n", "line 3: duplicate table: users"},
+		{"yaml duplicate tables", SchemaYAML, "tables:\n  users:\n    id: int\ntables:\n  orders:\n    id: int\n", "line 4: duplicate key: tables"},
+		{"yaml bad values", SchemaYAML, "tables:\n  users:\n    id: int\n    status: {type: enum, values: [a, 1]}\n", "line 4: column users.status: values must be a list of strings"},
+		{"yaml unknown column key", SchemaYAML, "tables:\n  users:\n    id: {type: int, primary: true}\n", "line 3: column users.id: unknown column key: primary"},
+		{"yaml table not a mapping", SchemaYAML, "tables:\n  users:\n    - id\n", "line 3: table users must be a mapping"},
+		{"yaml unknown key", SchemaYAML, "tables: {}\nother: {}\n", "line 2: unknown key: other"},
+		{"yaml syntax", SchemaYAML, "tables:\n  users:\n    id: int\n    name: [text\n", "yaml: line "},
+		{"yaml empty", SchemaYAML, "", "line 1: tables required"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseSchema([]byte(tt.data), tt.format)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("ParseSchema error = %v, want %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseSchemaFormats(t *testing.T) {
+	want := Schema{
+		"users": {
+			"id":     {Type: TypeInt, Unique: true},
+			"name":   {Type: TypeText},
+			"email":  {Type: TypeText, Nullable: true},
+			"status": {Type: TypeEnum, Values: []string{"active", "disabled"}},
+			"notes":  {},
+		},
+		"tags": {},
+	}
+	yamlDoc := `tables:
+  users:
+    id: {type: int, unique: true}
+    name: text
+    email: {type: text, nullable: true}
+    status: {type: enum, values: [active, disabled]}
+    notes: true
+    legacy: false
+  tags:
+`
+	jsonDoc := `{"tables": {
+  "users": {
+    "id": {"type": "int", "unique": true},
+    "name": "text",
+    "email": {"type": "text", "nullable": true},
+    "status": {"type": "enum", "values": ["active", "disabled"]},
+    "notes": true,
+    "legacy": false
+  },
+  "tags": {}
+}}`
+	for format, doc := range map[SchemaFormat]string{SchemaYAML: yamlDoc, SchemaJSON: jsonDoc} {
+		got, err := ParseSchema([]byte(doc), format)
+		if err != nil {
+			t.Fatalf("format %d: %v", format, err)
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("format %d: ParseSchema =\n%+v\nwant\n%+v", format, got, want)
+		}
+
+		// MarshalSchema must produce a file that parses back to the same schema.
+		data, err := MarshalSchema(want, format)
+		if err != nil {
+			t.Fatalf("format %d: %v", format, err)
+		}
+		again, err := ParseSchema(data, format)
+		if err != nil {
+			t.Fatalf("format %d: parse marshalled schema: %v\n%s", format, err, data)
+		}
+		if !reflect.DeepEqual(again, want) {
+			t.Errorf("format %d: round trip =\n%+v\nwant\n%+v", format, again, want)
+		}
+	}
+}
+
+func TestLoadSchemaFile(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, data string) string {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(data), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+
+	for _, path := range []string{
+		write("schema.yml", "tables:\n  users:\n    id: int\n"),
+		write("schema.YAML", "tables:\n  users:\n    id: int\n"),
+		write("schema.json", `{"tables": {"users": {"id": "int"}}}`),
+	} {
+		s, err := LoadSchemaFile(path)
+		if err != nil {
+			t.Fatalf("%s: %v", path, err)
+		}
+		if want := (Schema{"users": {"id": {Type: TypeInt}}}); !reflect.DeepEqual(s, want) {
+			t.Errorf("%s: got %+v", path, s)
+		}
+	}
+
+	bad := write("bad.yaml", "tables:\n  users:\n    id: integer\n")
+	if _, err := LoadSchemaFile(bad); err == nil || !strings.HasPrefix(err.Error(), bad+": line 3:") {
+		t.Errorf("LoadSchemaFile error = %v, want it to name the file and line", err)
+	}
+	if _, err := LoadSchemaFile(write("schema.toml", "")); err == nil {
+		t.Error("expected an error for an unknown extension")
+	}
+}
@@ -0,0 +1,102 @@
+package query_builder
+
+import (
+	"fmt"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// SchemaWatcher keeps a schema file loaded and reloads it when it changes.
+//
+// Each reload parses into a fresh Schema and swaps it in atomically; a Schema
+// returned by Schema is never modified afterwards. Services can therefore call
+// WithTypedSchema(w.Schema()) for every query while reloads happen, and each
+// Build sees one consistent version of the file.
+type SchemaWatcher struct {
+	path     string        // File being watched
+	interval time.Duration // Time between checks for changes
+	onError  func(error)   // Called when a reload fails; may be nil
+	current  atomic.Value  // The latest Schema that loaded successfully
+	modTime  time.Time     // Modification time of the file at the last load attempt
+	size     int64         // Size of the file at the last load attempt
+	stop     chan struct{} // Closed by Close to stop polling
+	done     chan struct{} // Closed when the polling goroutine exits
+	once     sync.Once     // Guards closing stop
+}
+
+// WatchSchemaFile loads the schema file at path and checks it for changes
+// every interval.
+//
+// The initial load must succeed. When a later reload fails, for example
+// because the file is mid-edit, the previous schema stays in use and onError,
+// if not nil, receives the error. Call Close to stop watching. interval must
+// be positive.
+func WatchSchemaFile(path string, interval time.Duration, onError func(error)) (*SchemaWatcher, error) {
+	if interval <= 0 {
+		return nil, fmt.Errorf("schema watch interval must be positive, got %v", interval)
+	}
+	w := &SchemaWatcher{
+		path:     path,
+		interval: interval,
+		onError:  onError,
+		stop:     make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+	if err := w.reload(); err != nil {
+		return nil, err
+	}
+	go w.poll()
+	return w, nil
+}
+
+// Schema returns the most recently loaded schema.
+func (w *SchemaWatcher) Schema() Schema {
+	return w.current.Load().(Schema)
+}
+
+// Close stops watching the file and waits for any reload in progress.
+func (w *SchemaWatcher) Close() {
+	w.once.Do(func() { close(w.stop) })
+	<-w.done
+}
+
+// poll checks the file every interval until Close is called.
+func (w *SchemaWatcher) poll() {
+	defer close(w.done)
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-w.stop:
+			return
+		case <-ticker.C:
+			if err := w.reload(); err != nil && w.onError != nil {
+				w.onError(err)
+			}
+		}
+	}
+}
+
+// reload parses the file again if its size or modification time changed.
+//
+// A file that failed to load is not retried until it changes again, so a bad
+// edit is reported once. Only the polling goroutine calls reload after
+// WatchSchemaFile returns, so modTime and size need no locking.
+func (w *SchemaWatcher) reload() error {
+	info, err := os.Stat(w.path)
+	if err != nil {
+		return err
+	}
+	if w.current.Load() != nil && info.ModTime().Equal(w.modTime) && info.Size() == w.size {
+		return nil
+	}
+	w.modTime, w.size = info.ModTime(), info.Size()
+	schema, err := LoadSchemaFile(w.path)
+	if err != nil {
+		return err
+	}
+	w.current.Store(schema)
+	return nil
+}
@@ -0,0 +1,89 @@
+package query_builder
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// replaceFile swaps in new contents with a rename, so the watcher never sees
+// the file half written.
+func replaceFile(t *testing.T, path, data string) {
+	t.Helper()
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, []byte(data), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestWatchSchemaFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "schema.yaml")
+	replaceFile(t, path, "tables:\n  users:\n    id: int\n")
+	errs := make(chan error, 10)
+	w, err := WatchSchemaFile(path, 5*time.Millisecond, func(err error) { errs <- err })
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+	first := w.Schema()
+
+	// waitFor polls until the watcher's schema satisfies ok.
+	waitFor := func(ok func(Schema) bool) {
+		t.Helper()
+		deadline := time.Now().Add(2 * time.Second)
+		for !ok(w.Schema()) {
+			if time.Now().After(deadline) {
+				t.Fatalf("schema not reloaded, have %+v", w.Schema())
+			}
+			time.Sleep(5 * time.Millisecond)
+		}
+	}
+
+	replaceFile(t, path, "tables:\n  users:\n    id: int\n    name: text\n")
+	waitFor(func(s Schema) bool { return len(s["users"]) == 2 })
+	if !reflect.DeepEqual(first, Schema{"users": {"id": {Type: TypeInt}}}) {
+		t.Errorf("earlier schema was modified by the reload: %+v", first)
+	}
+
+	// A broken edit is reported once and the last good schema stays in use.
+	replaceFile(t, path, "tables:\n  users:\n    id: integer\n")
+	select {
+	case err := <-errs:
+		if !strings.Contains(err.Error(), "line 3: column users.id: unknown column type: integer") {
+			t.Errorf("onError got %v", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("onError not called for a broken file")
+	}
+	if len(w.Schema()["users"]) != 2 {
+		t.Errorf("schema replaced by a failed reload: %+v", w.Schema())
+	}
+	time.Sleep(50 * time.Millisecond)
+	if len(errs) != 0 {
+		t.Errorf("unchanged broken file reported %d more times", len(errs))
+	}
+
+	replaceFile(t, path, "tables:\n  orders:\n    id: int\n")
+	waitFor(func(s Schema) bool { return s["orders"] != nil })
+}
+
+func TestWatchSchemaFileErrors(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "schema.json")
+	if _, err := WatchSchemaFile(path, time.Second, nil); err == nil {
+		t.Error("expected an error for a missing file")
+	}
+	if err := os.WriteFile(path, []byte(`{"tables": {}}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	for _, interval := range []time.Duration{0, -time.Second} {
+		if _, err := WatchSchemaFile(path, interval, nil); err == nil {
+			t.Errorf("expected an error for interval %v", interval)
+		}
+	}
+}
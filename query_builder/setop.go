@@ -0,0 +1,222 @@
+package query_builder
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// SetQuery combines several queries with UNION, UNION ALL, INTERSECT or EXCEPT.
+//
+// A SetQuery is created from its first part with Query.Union, Query.UnionAll,
+// Query.Intersect or Query.Except, and is rendered with that part's dialect,
+// schema and quoting settings.
+type SetQuery struct {
+	first  *Query    // The leftmost part, which also provides the output column names
+	parts  []setPart // Remaining parts, combined left to right
+	sorts  []Sort    // ORDER BY applied to the combined result
+	limit  int       // Maximum rows to fetch from the combined result
+	offset int       // Rows to skip in the combined result
+}
+
+// setPart is a single query joined to the previous ones by a set operator.
+type setPart struct {
+	Op    string // UNION, UNION ALL, INTERSECT or EXCEPT
+	Query *Query // The query being combined
+}
+
+// Union returns a SetQuery combining q with others using UNION.
+func (q *Query) Union(others ...*Query) *SetQuery {
+	return (&SetQuery{first: q}).add("UNION", others)
+}
+
+// UnionAll returns a SetQuery combining q with others using UNION ALL.
+func (q *Query) UnionAll(others ...*Query) *SetQuery {
+	return (&SetQuery{first: q}).add("UNION ALL", others)
+}
+
+// Intersect returns a SetQuery combining q with others using INTERSECT.
+func (q *Query) Intersect(others ...*Query) *SetQuery {
+	return (&SetQuery{first: q}).add("INTERSECT", others)
+}
+
+// Except returns a SetQuery combining q with others using EXCEPT.
+//
+// Dialects with a different spelling, such as Oracle's MINUS, use it instead.
+func (q *Query) Except(others ...*Query) *SetQuery {
+	return (&SetQuery{first: q}).add("EXCEPT", others)
+}
+
+// Union appends more parts using UNION.
+func (s *SetQuery) Union(others ...*Query) *SetQuery {
+	return s.add("UNION", others)
+}
+
+// UnionAll appends more parts using UNION ALL.
+func (s *SetQuery) UnionAll(others ...*Query) *SetQuery {
+	return s.add("UNION ALL", others)
+}
+
+// Intersect appends more parts using INTERSECT.
+func (s *SetQuery) Intersect(others ...*Query) *SetQuery {
+	return s.add("INTERSECT", others)
+}
+
+// Except appends more parts using EXCEPT.
+func (s *SetQuery) Except(others ...*Query) *SetQuery {
+	return s.add("EXCEPT", others)
+}
+
+// add appends others, each joined by op.
+func (s *SetQuery) add(op string, others []*Query) *SetQuery {
+	for _, o := range others {
+		s.parts = append(s.parts, setPart{Op: op, Query: o})
+	}
+	return s
+}
+
+// OrderBy appends a sort on an output column of the combined result.
+//
+// column is an output column name of the first part, without a table alias.
+func (s *SetQuery) OrderBy(column string, dir string) *SetQuery {
+	s.sorts = append(s.sorts, Sort{Column: Col(column), Dir: strings.ToUpper(dir)})
+	return s
+}
+
+// Limit sets the maximum number of rows to return from the combined result.
+func (s *SetQuery) Limit(limit int) *SetQuery {
+	s.limit = limit
+	return s
+}
+
+// Offset sets the number of rows of the combined result to skip.
+func (s *SetQuery) Offset(offset int) *SetQuery {
+	s.offset = offset
+	return s
+}
+
+// Build renders the combined statement and bound arguments.
+//
+// Placeholders are numbered in a single sequence across every part.
+func (s *SetQuery) Build() (string, []interface{}, error) {
+	q := s.first
+	if q == nil {
+		return "", nil, errors.New("set operation requires a first query")
+	}
+	if err := checkDialect(q.dialect); err != nil {
+		return "", nil, err
+	}
+	if len(s.parts) == 0 {
+		return "", nil, errors.New("set operation requires at least two queries")
+	}
+
+	// Databases disagree on whether INTERSECT binds tighter than UNION and
+	// EXCEPT, so mixing them would silently change meaning across dialects.
+	hasIntersect, hasOther := false, false
+	for _, p := range s.parts {
+		if p.Op == "INTERSECT" {
+			hasIntersect = true
+		} else {
+			hasOther = true
+		}
+	}
+	if hasIntersect && hasOther {
+		return "", nil, errors.New("cannot mix INTERSECT with UNION or EXCEPT in one set operation")
+	}
+
+	outputs, err := q.setPartColumns(q)
+	if err != nil {
+		return "", nil, err
+	}
+	for n, p := range s.parts {
+		cols, err := q.setPartColumns(p.Query)
+		if err != nil {
+			return "", nil, err
+		}
+		if len(cols) != len(outputs) {
+			return "", nil, fmt.Errorf("set operation part %d selects %d columns, expected %d", n+2, len(cols), len(outputs))
+		}
+		// Only the leading WITH of the whole statement is valid SQL, and that
+		// belongs to the first part.
+		if len(p.Query.ctes) > 0 {
+			return "", nil, fmt.Errorf("set operation part %d cannot have its own WITH clause", n+2)
+		}
+	}
+
+	var sb strings.Builder
+	var args []interface{}
+
+	first, err := q.renderSetPart(q, &args)
+	if err != nil {
+		return "", nil, err
+	}
+	sb.WriteString(first)
+	for _, p := range s.parts {
+		part, err := q.renderSetPart(p.Query, &args)
+		if err != nil {
+			return "", nil, err
+		}
+		sb.WriteString(fmt.Sprintf(" %s %s", q.setOperator(p.Op), part))
+	}
+
+	if len(s.sorts) > 0 {
+		names := columnSet(outputs)
+		var sortParts []string
+		for _, srt := range s.sorts {
+			if srt.Column.TableAlias != "" || !names[srt.Column.ColumnName] {
+				return "", nil, fmt.Errorf("invalid sort column: %s is not an output column", srt.Column.ColumnName)
+			}
+			dir := strings.ToUpper(srt.Dir)
+			if !allowedSortDir[dir] {
+				return "", nil, fmt.Errorf("invalid sort direction: %s", srt.Dir)
+			}
+			sortParts = append(sortParts, fmt.Sprintf("%s %s", q.quoteIdent(srt.Column.ColumnName), dir))
+		}
+		sb.WriteString(" ORDER BY " + strings.Join(sortParts, ", "))
+	}
+	sorted := len(s.sorts) > 0
+	if !sorted && (s.limit > 0 || s.offset > 0) && q.caps().Pagination == PaginationTop && len(outputs) > 0 {
+		// ORDER BY (SELECT NULL) is rejected here, because ORDER BY items of a
+		// compound statement must appear in its select list.
+		sb.WriteString(" ORDER BY " + q.quoteIdent(outputs[0]))
+		sorted = true
+	}
+	q.writeLimitOffset(&sb, &args, s.limit, s.offset, sorted)
+
+	if err := checkParamLimit(q.dialect, args); err != nil {
+		return "", nil, err
+	}
+	return sb.String(), args, nil
+}
+
+// setPartColumns returns the output columns of a part, which must be explicit
+// unless schema validation can expand base.*.
+func (q *Query) setPartColumns(part *Query) ([]string, error) {
+	if part == nil {
+		return nil, errors.New("set operation part required")
+	}
+	schema := nestedSchema(part, q.allowedSchema, nil)
+	if (len(part.projections) == 0 || part.selectsStar()) && !part.isCount && schema == nil {
+		return nil, errors.New("set operation parts must select explicit columns")
+	}
+	return part.outputColumns(schema)
+}
+
+// renderSetPart renders one part as a subquery of q.
+//
+// Parts may not sort or paginate on their own, because several databases
+// reject ORDER BY and LIMIT inside a compound statement.
+func (q *Query) renderSetPart(part *Query, args *[]interface{}) (string, error) {
+	if len(part.sorts) > 0 || part.limit > 0 || part.offset > 0 || part.pagination.Type != "" {
+		return "", errors.New("set operation parts cannot use ORDER BY or pagination; apply them to the combined result")
+	}
+	return q.renderSubquery(namedCount(part), args, nil, q.allowedSchema, nil)
+}
+
+// setOperator returns the dialect's spelling of a set operator.
+func (q *Query) setOperator(op string) string {
+	if kw := q.caps().ExceptKeyword; kw != "" && op == "EXCEPT" {
+		return kw
+	}
+	return op
+}
@@ -0,0 +1,52 @@
+package query_builder
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSetQuery(t *testing.T) {
+	users := func(d Dialect) *Query { return New(d).From("users", "u").Select("u.id") }
+	admins := func(d Dialect) *Query { return New(d).From("admins", "a").Select("a.id") }
+	recent := New(PostgresDialect{}).From("orders", "o").Select("o.user_id")
+	tests := []struct {
+		name    string
+		s       *SetQuery
+		want    string
+		wantErr string
+	}{
+		{"union", users(PostgresDialect{}).Union(admins(PostgresDialect{})).OrderBy("id", "desc").Limit(5),
+			`SELECT "u"."id" FROM "users" "u" UNION SELECT "a"."id" FROM "admins" "a" ORDER BY "id" DESC LIMIT $1`, ""},
+		{"cte on first part", New(PostgresDialect{}).With("r", recent).From("r", "r").Select("r.user_id").Union(admins(PostgresDialect{})),
+			`WITH "r" AS (SELECT "o"."user_id" FROM "orders" "o") SELECT "r"."user_id" FROM "r" "r" UNION SELECT "a"."id" FROM "admins" "a"`, ""},
+		{"cte on later part", users(PostgresDialect{}).Union(New(PostgresDialect{}).With("r", recent).From("r", "r").Select("r.user_id")),
+			"", "set operation part 2 cannot have its own WITH clause"},
+		{"column count", users(PostgresDialect{}).Union(New(PostgresDialect{}).From("admins", "a").Select("a.id", "a.name")),
+			"", "selects 2 columns, expected 1"},
+		{"mixed operators", users(PostgresDialect{}).Union(admins(PostgresDialect{})).Intersect(admins(PostgresDialect{})),
+			"", "cannot mix INTERSECT"},
+		{"sqlserver page without sort", users(SQLServerDialect{}).Union(admins(SQLServerDialect{})).Limit(10),
+			`SELECT [u].[id] FROM [users] [u] UNION SELECT [a].[id] FROM [admins] [a] ORDER BY [id] OFFSET @p1 ROWS FETCH NEXT @p2 ROWS ONLY`, ""},
+		{"sqlserver page with sort", users(SQLServerDialect{}).Union(admins(SQLServerDialect{})).OrderBy("id", "DESC").Offset(20),
+			`SELECT [u].[id] FROM [users] [u] UNION SELECT [a].[id] FROM [admins] [a] ORDER BY [id] DESC OFFSET @p1 ROWS`, ""},
+		{"oracle except", users(OracleDialect{}).Except(admins(OracleDialect{})),
+			`SELECT "u"."id" FROM "users" "u" MINUS SELECT "a"."id" FROM "admins" "a"`, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sql, _, err := tt.s.Build()
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("Build error = %v, want %q; sql = %s", err, tt.wantErr, sql)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if sql != tt.want {
+				t.Errorf("sql =\n%s\nwant\n%s", sql, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,99 @@
+package query_builder
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// SQLiteDialect implements Dialect for SQLite, using ? (or ?NNN) placeholders and double quotes.
+//
+// Version optionally names the oldest SQLite release the SQL must run on
+// (e.g., "3.31.1"). Features that need a newer release are then rejected by
+// Build. An empty Version assumes a current release; a malformed one makes
+// Build fail.
+type SQLiteDialect struct {
+	Numbered bool   // If true, placeholders are ?1, ?2, etc. instead of ?
+	Version  string // Minimum SQLite version to target, as "major.minor[.patch]"
+}
+
+// Placeholder returns ? or, when Numbered is set, ?1, ?2, etc.
+func (s SQLiteDialect) Placeholder(index int) string {
+	if s.Numbered {
+		return fmt.Sprintf("?%d", index)
+	}
+	return "?"
+}
+
+// QuoteIdentifier returns "name", doubling any embedded double quotes.
+func (s SQLiteDialect) QuoteIdentifier(name string) string {
+	return fmt.Sprintf("\"%s\"", strings.ReplaceAll(name, "\"", "\"\""))
+}
+
+// Capabilities reports the features of the targeted SQLite release.
+//
+// RETURNING needs 3.35, ON CONFLICT upserts 3.24, RIGHT and FULL joins 3.39,
+// NULLS FIRST/LAST 3.30, row values 3.15 and TRUE/FALSE 3.23. Releases before
+// 3.32 accept at most 999 parameters.
+func (s SQLiteDialect) Capabilities() Capabilities {
+	c := Capabilities{
+		Pagination:      PaginationLimitRequired,
+		RowValues:       s.atLeast(3, 15),
+		NullsOrdering:   s.atLeast(3, 30),
+		BooleanLiterals: s.atLeast(3, 23),
+		MaxParams:       32766,
+		DMLAlias:        DMLAliasAs,
+	}
+	if s.atLeast(3, 35) {
+		c.Returning = ReturningClause
+	}
+	if s.atLeast(3, 24) {
+		c.Upsert = UpsertOnConflict
+	}
+	if !s.atLeast(3, 39) {
+		c.UnsupportedJoins = []string{"RIGHT", "FULL"}
+	}
+	if !s.atLeast(3, 32) {
+		c.MaxParams = 999
+	}
+	return c
+}
+
+// atLeast reports whether the targeted version is major.minor or newer.
+//
+// An empty Version is treated as a current release. Build rejects an
+// invalid Version before any feature is checked.
+func (s SQLiteDialect) atLeast(major, minor int) bool {
+	maj, min, err := s.version()
+	if s.Version == "" || err != nil {
+		return true
+	}
+	return maj > major || (maj == major && min >= minor)
+}
+
+// version parses Version as "major.minor[.patch]".
+func (s SQLiteDialect) version() (major, minor int, err error) {
+	parts := strings.Split(s.Version, ".")
+	if len(parts) < 2 || len(parts) > 3 {
+		return 0, 0, fmt.Errorf("invalid SQLite version %q: want major.minor[.patch]", s.Version)
+	}
+	nums := make([]int, len(parts))
+	for n, p := range parts {
+		v, err := strconv.Atoi(p)
+		if err != nil || v < 0 || p != strconv.Itoa(v) {
+			return 0, 0, fmt.Errorf("invalid SQLite version %q: want major.minor[.patch]", s.Version)
+		}
+		nums[n] = v
+	}
+	return nums[0], nums[1], nil
+}
+
+// checkSettings reports an invalid Version, which would otherwise silently
+// enable every feature.
+func (s SQLiteDialect) checkSettings() error {
+	if s.Version == "" {
+		return nil
+	}
+	_, _, err := s.version()
+	return err
+}
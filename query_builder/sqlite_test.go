@@ -0,0 +1,64 @@
+package query_builder
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSQLiteVersion(t *testing.T) {
+	rightJoin := func(d SQLiteDialect) *Query {
+		return New(d).From("users", "u").Join("RIGHT", "orders", "o", "u.id", "o.user_id", "=")
+	}
+	tests := []struct {
+		version string
+		wantErr string
+	}{
+		{"", ""},
+		{"3.39", ""},
+		{"3.39.4", ""},
+		{"3.38.5", "RIGHT"},
+		{"v3.30", `invalid SQLite version "v3.30"`},
+		{"3.x", `invalid SQLite version "3.x"`},
+		{"3", `invalid SQLite version "3"`},
+		{"3.40.1.2", `invalid SQLite version "3.40.1.2"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.version, func(t *testing.T) {
+			_, _, err := rightJoin(SQLiteDialect{Version: tt.version}).Build()
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("Build error = %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Build error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+
+	// Every kind of statement rejects a malformed version.
+	d := SQLiteDialect{Version: "3.x"}
+	builds := map[string]func() (string, []interface{}, error){
+		"insert": New(d).Insert("users").Columns("id").Values(1).Build,
+		"update": New(d).Update("users", "").Set("id", 1).Build,
+		"delete": New(d).Delete("users", "").Build,
+		"union":  New(d).From("users", "u").Select("u.id").Union(New(d).From("admins", "a").Select("a.id")).Build,
+	}
+	for name, build := range builds {
+		if _, _, err := build(); err == nil || !strings.Contains(err.Error(), "invalid SQLite version") {
+			t.Errorf("%s: Build error = %v, want an invalid version error", name, err)
+		}
+	}
+}
+
+func TestSQLiteCapabilities(t *testing.T) {
+	old := SQLiteDialect{Version: "3.22.0"}.Capabilities()
+	if old.Returning != ReturningNone || old.Upsert != UpsertNone || old.BooleanLiterals || old.MaxParams != 999 {
+		t.Errorf("3.22 capabilities = %+v", old)
+	}
+	cur := SQLiteDialect{}.Capabilities()
+	if cur.Returning != ReturningClause || cur.Upsert != UpsertOnConflict || !cur.BooleanLiterals || cur.MaxParams != 32766 || len(cur.UnsupportedJoins) != 0 {
+		t.Errorf("current capabilities = %+v", cur)
+	}
+}
@@ -0,0 +1,39 @@
+package query_builder
+
+import (
+	"fmt"
+	"strings"
+)
+
+// sqlServerMaxParams is the most parameters SQL Server accepts in one request.
+const sqlServerMaxParams = 2100
+
+// sqlServerMaxInsertRows is the most row value expressions SQL Server accepts
+// in one INSERT ... VALUES.
+const sqlServerMaxInsertRows = 1000
+
+// SQLServerDialect implements Dialect for Microsoft SQL Server, using @p1, @p2 placeholders and [name] quoting.
+type SQLServerDialect struct{}
+
+// Placeholder returns @p1, @p2, etc.
+func (s SQLServerDialect) Placeholder(index int) string {
+	return fmt.Sprintf("@p%d", index)
+}
+
+// QuoteIdentifier returns [name], doubling any embedded closing brackets.
+func (s SQLServerDialect) QuoteIdentifier(name string) string {
+	return fmt.Sprintf("[%s]", strings.ReplaceAll(name, "]", "]]"))
+}
+
+// Capabilities reports the features of SQL Server: TOP and OFFSET ... FETCH
+// pagination, a limit of 2100 parameters per request and 1000 rows per
+// INSERT ... VALUES, and UPDATE ... FROM aliasing.
+func (s SQLServerDialect) Capabilities() Capabilities {
+	return Capabilities{
+		Pagination:      PaginationTop,
+		MaxParams:       sqlServerMaxParams,
+		MaxInsertRows:   sqlServerMaxInsertRows,
+		ImplicitRecurse: true,
+		DMLAlias:        DMLAliasFrom,
+	}
+}
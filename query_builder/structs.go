@@ -0,0 +1,209 @@
+package query_builder
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// TableNamer is implemented by model structs that name their own table.
+type TableNamer interface {
+	TableName() string
+}
+
+// SchemaFromStructs derives a typed Schema from tagged model structs.
+//
+// Each argument is a struct or a pointer to one. Its table name comes from a
+// TableName method or, failing that, a `table:"name"` tag on any field
+// (typically a blank `_ struct{}` field). Every exported field with a
+// `db:"column"` tag becomes a column; `db:"-"` and untagged fields are
+// skipped, and embedded structs without a db tag contribute their fields.
+//
+// Column types are inferred from the Go field type. Pointers and the
+// sql.Null* types mark a column nullable. Extra comma-separated tag options
+// refine the result:
+//
+//	ID     string `db:"id,unique,type=uuid"`
+//	Status string `db:"status,enum=active|disabled"`
+//	Note   string `db:"note,nullable"`
+//
+// Other options, such as the omitempty used by some mappers, are ignored, so
+// existing models can be passed as they are.
+//
+// Pass the result to WithTypedSchema, or use Schema.AllowList with WithSchema.
+func SchemaFromStructs(tables ...interface{}) (Schema, error) {
+	schema := make(Schema, len(tables))
+	for _, model := range tables {
+		t := reflect.TypeOf(model)
+		for t != nil && t.Kind() == reflect.Ptr {
+			t = t.Elem()
+		}
+		if t == nil || t.Kind() != reflect.Struct {
+			return nil, fmt.Errorf("schema model must be a struct, got %T", model)
+		}
+
+		name := structTableName(model, t)
+		if name == "" {
+			return nil, fmt.Errorf("table name required for %s: add a TableName method or a table tag", t.String())
+		}
+		if _, exists := schema[name]; exists {
+			return nil, fmt.Errorf("duplicate schema table: %s", name)
+		}
+
+		cols := make(Table)
+		if err := collectStructColumns(t, cols); err != nil {
+			return nil, fmt.Errorf("invalid model %s: %v", t.String(), err)
+		}
+		if len(cols) == 0 {
+			return nil, fmt.Errorf("model %s has no db columns", t.String())
+		}
+		schema[name] = cols
+	}
+	return schema, nil
+}
+
+// structTableName returns the table name from a TableName method or table tag.
+//
+// A nil pointer such as (*User)(nil) is resolved through a new zero value, so
+// a TableName method with a value receiver is never called on nil.
+func structTableName(model interface{}, t reflect.Type) string {
+	if v := reflect.ValueOf(model); v.Kind() != reflect.Ptr || !v.IsNil() {
+		if n, ok := model.(TableNamer); ok {
+			return n.TableName()
+		}
+	}
+	if n, ok := reflect.New(t).Interface().(TableNamer); ok {
+		return n.TableName()
+	}
+	for i := 0; i < t.NumField(); i++ {
+		if name, ok := t.Field(i).Tag.Lookup("table"); ok {
+			return name
+		}
+	}
+	return ""
+}
+
+// collectStructColumns adds the db-tagged fields of t, including those of
+// embedded structs, to cols.
+func collectStructColumns(t reflect.Type, cols Table) error {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag, tagged := field.Tag.Lookup("db")
+		if tag == "-" {
+			continue
+		}
+
+		if field.Anonymous && !tagged {
+			ft := field.Type
+			if ft.Kind() == reflect.Ptr {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct {
+				if err := collectStructColumns(ft, cols); err != nil {
+					return err
+				}
+			}
+			continue
+		}
+		if !tagged || field.PkgPath != "" {
+			continue
+		}
+
+		opts := strings.Split(tag, ",")
+		name := opts[0]
+		if name == "" {
+			return fmt.Errorf("empty db tag on field %s", field.Name)
+		}
+		if _, exists := cols[name]; exists {
+			return fmt.Errorf("duplicate column: %s", name)
+		}
+		col := inferColumn(field.Type)
+		if err := applyColumnOptions(&col, opts[1:]); err != nil {
+			return fmt.Errorf("field %s: %v", field.Name, err)
+		}
+		cols[name] = col
+	}
+	return nil
+}
+
+// Types with a fixed column mapping.
+var (
+	timeType       = reflect.TypeOf(time.Time{})
+	rawMessageType = reflect.TypeOf(json.RawMessage{})
+	nullTypes      = map[reflect.Type]ColumnType{
+		reflect.TypeOf(sql.NullString{}):  TypeText,
+		reflect.TypeOf(sql.NullInt64{}):   TypeInt,
+		reflect.TypeOf(sql.NullInt32{}):   TypeInt,
+		reflect.TypeOf(sql.NullInt16{}):   TypeInt,
+		reflect.TypeOf(sql.NullByte{}):    TypeInt,
+		reflect.TypeOf(sql.NullFloat64{}): TypeFloat,
+		reflect.TypeOf(sql.NullBool{}):    TypeBool,
+		reflect.TypeOf(sql.NullTime{}):    TypeTimestamp,
+	}
+)
+
+// inferColumn maps a Go field type to a column definition.
+func inferColumn(t reflect.Type) Column {
+	if ct, ok := nullTypes[t]; ok {
+		return Column{Type: ct, Nullable: true}
+	}
+	if t.Kind() == reflect.Ptr {
+		col := inferColumn(t.Elem())
+		col.Nullable = true
+		return col
+	}
+	switch {
+	case t == timeType:
+		return Column{Type: TypeTimestamp}
+	case t == rawMessageType:
+		return Column{Type: TypeJSON}
+	case t.Kind() == reflect.Array && t.Len() == 16 && t.Elem().Kind() == reflect.Uint8:
+		return Column{Type: TypeUUID}
+	}
+	switch t.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return Column{Type: TypeInt}
+	case reflect.Float32, reflect.Float64:
+		return Column{Type: TypeFloat}
+	case reflect.String:
+		return Column{Type: TypeText}
+	case reflect.Bool:
+		return Column{Type: TypeBool}
+	}
+	return Column{Type: TypeAny}
+}
+
+// applyColumnOptions applies db tag options such as "unique" or "type=uuid".
+//
+// Options meant for other libraries are skipped.
+func applyColumnOptions(col *Column, opts []string) error {
+	for _, opt := range opts {
+		key, val := opt, ""
+		if eq := strings.IndexByte(opt, '='); eq >= 0 {
+			key, val = opt[:eq], opt[eq+1:]
+		}
+		switch strings.TrimSpace(key) {
+		case "unique":
+			col.Unique = true
+		case "nullable":
+			col.Nullable = true
+		case "type":
+			if !knownColumnTypes[ColumnType(val)] {
+				return fmt.Errorf("unknown column type: %s", val)
+			}
+			col.Type = ColumnType(val)
+		case "enum":
+			if val == "" {
+				return errors.New("enum option requires values")
+			}
+			col.Type = TypeEnum
+			col.Values = strings.Split(val, "|")
+		}
+	}
+	return nil
+}
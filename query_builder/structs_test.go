@@ -0,0 +1,77 @@
+package query_builder
+
+import (
+	"reflect"
+	"testing"
+)
+
+type valueNamedModel struct {
+	ID int `db:"id"`
+}
+
+func (valueNamedModel) TableName() string { return "users" }
+
+type pointerNamedModel struct {
+	ID *int `db:"id"`
+}
+
+func (*pointerNamedModel) TableName() string { return "orders" }
+
+type taggedModel struct {
+	_  struct{} `table:"tags"`
+	ID int      `db:"id,unique"`
+}
+
+func TestSchemaFromStructsTableNames(t *testing.T) {
+	want := Schema{
+		"users":  {"id": {Type: TypeInt}},
+		"orders": {"id": {Type: TypeInt, Nullable: true}},
+		"tags":   {"id": {Type: TypeInt, Unique: true}},
+	}
+	for _, models := range [][]interface{}{
+		{valueNamedModel{}, &pointerNamedModel{}, taggedModel{}},
+		// Nil pointers are a common way to name a model type.
+		{(*valueNamedModel)(nil), (*pointerNamedModel)(nil), (*taggedModel)(nil)},
+	} {
+		got, err := SchemaFromStructs(models...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("SchemaFromStructs(%#v) =\n%+v\nwant\n%+v", models, got, want)
+		}
+	}
+}
+
+type sqlxModel struct {
+	_      struct{} `table:"accounts"`
+	ID     int64    `db:"id,omitempty,unique"`
+	Status string   `db:"status,enum=active|disabled"`
+	Email  *string  `db:"email,omitempty"`
+	Secret string   `db:"-"`
+}
+
+type badTypeModel struct {
+	_  struct{} `table:"bad"`
+	ID int      `db:"id,type=integer"`
+}
+
+func TestSchemaFromStructsOptions(t *testing.T) {
+	got, err := SchemaFromStructs(sqlxModel{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := Schema{"accounts": {
+		"id":     {Type: TypeInt, Unique: true},
+		"status": {Type: TypeEnum, Values: []string{"active", "disabled"}},
+		"email":  {Type: TypeText, Nullable: true},
+	}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("SchemaFromStructs =\n%+v\nwant\n%+v", got, want)
+	}
+
+	// Options this package owns are still checked.
+	if _, err := SchemaFromStructs(badTypeModel{}); err == nil {
+		t.Error("expected an error for an unknown column type")
+	}
+}
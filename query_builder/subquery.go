@@ -0,0 +1,96 @@
+package query_builder
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Exists returns a filter that is true when sub yields at least one row.
+//
+// sub may reference aliases of the enclosing query to form a correlated subquery.
+func Exists(sub *Query) Filter {
+	return Filter{Op: "EXISTS", Value: sub}
+}
+
+// NotExists returns a filter that is true when sub yields no rows.
+//
+// sub may reference aliases of the enclosing query to form a correlated subquery.
+func NotExists(sub *Query) Filter {
+	return Filter{Op: "NOT EXISTS", Value: sub}
+}
+
+// renderSubquery renders sub inside q, continuing q's placeholder sequence.
+// Filter subqueries, CTE members and set operation parts all go through it,
+// so they inherit the same settings.
+//
+// The subquery is rendered with q's dialect and quoting mode. It keeps its own
+// schema and field map and otherwise inherits q's: schema is the enclosing
+// allow-list, and virtual adds tables such as previously rendered CTEs. aliasMap
+// makes the enclosing aliases visible to correlated references.
+func (q *Query) renderSubquery(sub *Query, args *[]interface{}, aliasMap map[string]string, schema, virtual map[string]map[string]bool) (string, error) {
+	if sub == nil {
+		return "", errors.New("subquery required")
+	}
+	if q.nesting >= maxFilterDepth {
+		return "", errors.New("subquery depth exceeded")
+	}
+	inner := *sub
+	inner.dialect = q.dialect
+	inner.unquoted = q.unquoted
+	inner.allowedSchema = nestedSchema(sub, schema, virtual)
+	if sub.allowedSchema == nil {
+		inner.typedSchema = q.typedSchema
+	}
+	if inner.fieldMap == nil {
+		inner.fieldMap = q.fieldMap
+	}
+	inner.nesting = q.nesting + 1
+	return inner.build(args, aliasMap)
+}
+
+// nestedSchema returns the allow-list a nested query is validated against: its
+// own, or the enclosing schema, extended with virtual tables.
+func nestedSchema(sub *Query, schema, virtual map[string]map[string]bool) map[string]map[string]bool {
+	if sub.allowedSchema != nil {
+		schema = sub.allowedSchema
+	}
+	if len(virtual) == 0 {
+		return schema
+	}
+	return mergeSchemas(schema, virtual)
+}
+
+// buildExists renders an EXISTS or NOT EXISTS filter.
+func (q *Query) buildExists(op string, sub *Query, args *[]interface{}, aliasMap map[string]string, schema map[string]map[string]bool) (string, error) {
+	op = strings.ToUpper(op)
+	if op != "EXISTS" && op != "NOT EXISTS" {
+		return "", fmt.Errorf("invalid subquery operator: %s", op)
+	}
+	inner, err := q.renderSubquery(sub, args, aliasMap, schema, nil)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%s (%s)", op, inner), nil
+}
+
+// buildInSubquery renders "left IN (SELECT ...)" or its NOT IN form.
+//
+// The subquery must project exactly one column.
+func (q *Query) buildInSubquery(left, op string, sub *Query, args *[]interface{}, aliasMap map[string]string, schema map[string]map[string]bool) (string, error) {
+	op = strings.ToUpper(op)
+	if op != "IN" && op != "NOT IN" {
+		return "", fmt.Errorf("invalid subquery operator: %s", op)
+	}
+	if sub == nil {
+		return "", errors.New("subquery required")
+	}
+	if !sub.isCount && len(sub.projections) != 1 {
+		return "", fmt.Errorf("%s subquery must select exactly one column", op)
+	}
+	inner, err := q.renderSubquery(sub, args, aliasMap, schema, nil)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%s %s (%s)", left, op, inner), nil
+}
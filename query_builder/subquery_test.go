@@ -0,0 +1,62 @@
+package query_builder
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestSubqueryFilters(t *testing.T) {
+	schema := map[string]map[string]bool{"users": {"id": true, "name": true}, "orders": {"id": true, "user_id": true, "total": true}}
+	users := func() *Query { return New(PostgresDialect{}).WithSchema(schema).From("users", "u").Select("u.id") }
+	orders := func() *Query { return New(MySQLDialect{}).From("orders", "o") }
+	tests := []struct {
+		name    string
+		q       *Query
+		want    string
+		args    []interface{}
+		wantErr string
+	}{
+		{"in", users().Where(And(F("u.name", "=", "ann"), F("u.id", "IN", orders().Select("o.user_id").Where(And(F("o.total", ">", 100)))))),
+			`SELECT "u"."id" FROM "users" "u" WHERE "u"."name" = $1 AND "u"."id" IN (SELECT "o"."user_id" FROM "orders" "o" WHERE "o"."total" > $2)`,
+			[]interface{}{"ann", 100}, ""},
+		{"not in", users().Where(And(F("u.id", "not in", orders().Select("o.user_id")))),
+			`SELECT "u"."id" FROM "users" "u" WHERE "u"."id" NOT IN (SELECT "o"."user_id" FROM "orders" "o")`, nil, ""},
+		{"correlated exists", users().Where(And(Exists(orders().Select("o.id").Where(And(FC("o.user_id", "=", "u.id")))), F("u.id", ">", 5))),
+			`SELECT "u"."id" FROM "users" "u" WHERE EXISTS (SELECT "o"."id" FROM "orders" "o" WHERE "o"."user_id" = "u"."id") AND "u"."id" > $1`,
+			[]interface{}{5}, ""},
+		{"not exists", users().Where(And(NotExists(orders().Select("o.id").Where(And(FC("o.user_id", "=", "u.id"), F("o.total", "<", 0)))))),
+			`SELECT "u"."id" FROM "users" "u" WHERE NOT EXISTS (SELECT "o"."id" FROM "orders" "o" WHERE "o"."user_id" = "u"."id" AND "o"."total" < $1)`,
+			[]interface{}{0}, ""},
+		{"two columns", users().Where(And(F("u.id", "IN", orders().Select("o.user_id", "o.id")))), "", nil, "IN subquery must select exactly one column"},
+		{"comparison", users().Where(And(F("u.id", "=", orders().Select("o.user_id")))), "", nil, "invalid subquery operator: ="},
+		{"unknown inner column", users().Where(And(Exists(orders().Select("o.id").Where(And(FC("o.user_id", "=", "u.missing")))))), "", nil, "invalid column: u.missing"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sql, args, err := tt.q.Build()
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("Build error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if sql != tt.want {
+				t.Errorf("sql =\n%s\nwant\n%s", sql, tt.want)
+			}
+			if !reflect.DeepEqual(args, tt.args) {
+				t.Errorf("args = %#v, want %#v", args, tt.args)
+			}
+		})
+	}
+
+	// A query that contains itself stops at the nesting limit.
+	loop := New(PostgresDialect{}).From("users", "u").Select("u.id")
+	loop.Where(And(F("u.id", "IN", loop)))
+	if _, _, err := loop.Build(); err == nil || !strings.Contains(err.Error(), "depth exceeded") {
+		t.Errorf("self-referencing Build error = %v", err)
+	}
+}
@@ -0,0 +1,180 @@
+package query_builder
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// UpdateQuery builds a SQL UPDATE statement.
+//
+// An UpdateQuery is created with Query.Update and shares the dialect, schema
+// and quoting settings of the Query it was created from.
+type UpdateQuery struct {
+	parent    *Query       // Query providing dialect, schema and quoting settings
+	table     string       // Target table
+	alias     string       // Alias for the target table
+	sets      []assignment // SET assignments, in order
+	where     *FilterGroup // Root filter group (WHERE clause)
+	fullTable bool         // If true, an UPDATE without WHERE is allowed
+}
+
+// assignment is a single "column = value" pair in a SET clause.
+type assignment struct {
+	Column ColumnRef   // The column being assigned
+	Value  interface{} // The new value (will be parameterized)
+}
+
+// Update returns an UpdateQuery for table using alias in the WHERE clause.
+//
+// If alias is empty, the table name is used as the alias.
+func (q *Query) Update(table string, alias string) *UpdateQuery {
+	return &UpdateQuery{parent: q, table: table, alias: alias}
+}
+
+// Set appends a "column = value" assignment.
+//
+// column may be either "column" or "alias.column".
+func (u *UpdateQuery) Set(column string, value interface{}) *UpdateQuery {
+	u.sets = append(u.sets, assignment{Column: Col(column), Value: value})
+	return u
+}
+
+// SetMap appends one assignment per map entry.
+//
+// Entries are added in sorted column order so the rendered SQL is stable.
+func (u *UpdateQuery) SetMap(values map[string]interface{}) *UpdateQuery {
+	columns := make([]string, 0, len(values))
+	for c := range values {
+		columns = append(columns, c)
+	}
+	sort.Strings(columns)
+	for _, c := range columns {
+		u.Set(c, values[c])
+	}
+	return u
+}
+
+// Where sets the root WHERE filter group.
+//
+// Use And and Or to compose nested conditions, exactly as with Query.Where.
+func (u *UpdateQuery) Where(group *FilterGroup) *UpdateQuery {
+	u.where = group
+	return u
+}
+
+// AllowFullTable permits rendering an UPDATE that has no WHERE clause.
+//
+// Without it, Build refuses to update every row of the table.
+func (u *UpdateQuery) AllowFullTable() *UpdateQuery {
+	u.fullTable = true
+	return u
+}
+
+// Build renders the UPDATE statement and bound arguments.
+//
+// SET and WHERE placeholders are numbered in a single sequence.
+func (u *UpdateQuery) Build() (string, []interface{}, error) {
+	q := u.parent
+	if len(q.errors) > 0 {
+		return "", nil, q.errors[0]
+	}
+	if err := checkDialect(q.dialect); err != nil {
+		return "", nil, err
+	}
+	if u.table == "" {
+		return "", nil, errors.New("update table required")
+	}
+	if q.allowedSchema != nil {
+		if _, ok := q.allowedSchema[u.table]; !ok {
+			return "", nil, fmt.Errorf("invalid update table: %s", u.table)
+		}
+	}
+	if len(u.sets) == 0 {
+		return "", nil, errors.New("update assignments required")
+	}
+
+	alias := u.getAlias()
+	aliasMap := map[string]string{alias: u.table}
+
+	var sb strings.Builder
+	var args []interface{}
+
+	aliasFrom := u.alias != "" && q.caps().DMLAlias == DMLAliasFrom
+	if aliasFrom {
+		sb.WriteString("UPDATE " + q.quoteIdent(u.alias) + " SET ")
+	} else {
+		sb.WriteString("UPDATE " + q.dmlTable(u.table, u.alias) + " SET ")
+	}
+	seen := make(map[string]bool)
+	var setParts []string
+	for _, s := range u.sets {
+		col := s.Column
+		if col.TableAlias == "" {
+			col.TableAlias = alias
+		}
+		if err := q.validateCol(col, aliasMap, q.allowedSchema); err != nil {
+			return "", nil, fmt.Errorf("invalid column: %v", err)
+		}
+		if col.TableAlias != alias {
+			return "", nil, fmt.Errorf("invalid update column: %s.%s", col.TableAlias, col.ColumnName)
+		}
+		if seen[col.ColumnName] {
+			return "", nil, fmt.Errorf("duplicate update column: %s", col.ColumnName)
+		}
+		seen[col.ColumnName] = true
+
+		val, err := q.coerceWrite(u.table, col.ColumnName, s.Value)
+		if err != nil {
+			return "", nil, err
+		}
+
+		// SET targets are never alias-qualified; Postgres rejects that form.
+		args = append(args, val)
+		setParts = append(setParts, fmt.Sprintf("%s = %s", q.quoteIdent(col.ColumnName), q.dialect.Placeholder(len(args))))
+	}
+	sb.WriteString(strings.Join(setParts, ", "))
+	if aliasFrom {
+		sb.WriteString(" FROM " + q.dmlTable(u.table, u.alias))
+	}
+
+	whereClause := ""
+	if u.where != nil {
+		clause, err := q.buildFilterGroup(*u.where, &args, aliasMap, 0, q.allowedSchema, nil)
+		if err != nil {
+			return "", nil, err
+		}
+		whereClause = clause
+	}
+	if whereClause != "" {
+		sb.WriteString(" WHERE " + whereClause)
+	} else if !u.fullTable {
+		return "", nil, errors.New("update without WHERE clause requires AllowFullTable")
+	}
+	if err := checkParamLimit(q.dialect, args); err != nil {
+		return "", nil, err
+	}
+	return sb.String(), args, nil
+}
+
+// getAlias returns the explicit alias or the table name if no alias exists.
+func (u *UpdateQuery) getAlias() string {
+	if u.alias != "" {
+		return u.alias
+	}
+	return u.table
+}
+
+// dmlTable renders the target table of an UPDATE or DELETE with its alias, if
+// any, in the dialect's DMLAlias style.
+func (q *Query) dmlTable(table, alias string) string {
+	if alias == "" {
+		return q.quoteIdent(table)
+	}
+	if q.caps().DMLAlias == DMLAliasAs {
+		return q.quoteIdent(table) + " AS " + q.quoteIdent(alias)
+	}
+	// DMLAliasFrom declares the alias the plain way inside its FROM clause.
+	return q.quoteIdent(table) + " " + q.quoteIdent(alias)
+}
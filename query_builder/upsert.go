@@ -0,0 +1,198 @@
+package query_builder
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// UpsertStyle describes how a dialect renders an insert-or-update statement.
+type UpsertStyle int
+
+const (
+	// UpsertNone means the dialect has no upsert support.
+	UpsertNone UpsertStyle = iota
+	// UpsertOnConflict renders INSERT ... ON CONFLICT (...) DO UPDATE (Postgres, SQLite).
+	UpsertOnConflict
+	// UpsertOnDuplicateKey renders INSERT ... ON DUPLICATE KEY UPDATE (MySQL).
+	UpsertOnDuplicateKey
+	// UpsertMerge renders MERGE INTO ... USING (SELECT ... FROM dual) (Oracle).
+	UpsertMerge
+)
+
+// upsert holds the conflict handling configured on an InsertQuery.
+type upsert struct {
+	keys      []string // Columns identifying a conflicting row
+	update    []string // Columns overwritten on conflict; empty means every non-key column
+	doNothing bool     // If true, conflicting rows are left untouched
+}
+
+// OnConflict turns the insert into an upsert keyed on the given columns.
+//
+// Key columns must also be listed in Columns. MySQL ignores the key list and
+// relies on the table's unique indexes instead.
+func (i *InsertQuery) OnConflict(keys ...string) *InsertQuery {
+	if i.upsert == nil {
+		i.upsert = &upsert{}
+	}
+	i.upsert.keys = append(i.upsert.keys, keys...)
+	return i
+}
+
+// DoUpdate sets the columns overwritten with incoming values on conflict.
+//
+// When DoUpdate is not called, every inserted column that is not a key is updated.
+func (i *InsertQuery) DoUpdate(columns ...string) *InsertQuery {
+	if i.upsert == nil {
+		i.upsert = &upsert{}
+	}
+	i.upsert.update = append(i.upsert.update, columns...)
+	return i
+}
+
+// DoNothing leaves conflicting rows untouched instead of updating them.
+func (i *InsertQuery) DoNothing() *InsertQuery {
+	if i.upsert == nil {
+		i.upsert = &upsert{}
+	}
+	i.upsert.doNothing = true
+	return i
+}
+
+// updateColumns returns the validated list of columns to overwrite on conflict.
+func (i *InsertQuery) updateColumns() ([]string, error) {
+	u := i.upsert
+	if len(u.keys) == 0 {
+		return nil, errors.New("upsert conflict columns required")
+	}
+	inserted := make(map[string]bool)
+	for _, c := range i.columns {
+		inserted[c] = true
+	}
+	isKey := make(map[string]bool)
+	for _, k := range u.keys {
+		if !inserted[k] {
+			return nil, fmt.Errorf("upsert conflict column not inserted: %s", k)
+		}
+		isKey[k] = true
+	}
+	if u.doNothing {
+		if len(u.update) > 0 {
+			return nil, errors.New("upsert cannot combine DoUpdate and DoNothing")
+		}
+		return nil, nil
+	}
+	if len(u.update) == 0 {
+		var cols []string
+		for _, c := range i.columns {
+			if !isKey[c] {
+				cols = append(cols, c)
+			}
+		}
+		return cols, nil
+	}
+	for _, c := range u.update {
+		if !inserted[c] {
+			return nil, fmt.Errorf("upsert update column not inserted: %s", c)
+		}
+		if isKey[c] {
+			return nil, fmt.Errorf("upsert cannot update conflict column: %s", c)
+		}
+	}
+	return u.update, nil
+}
+
+// buildUpsert renders the upsert form of the insert for the dialect.
+func (i *InsertQuery) buildUpsert() (string, []interface{}, error) {
+	update, err := i.updateColumns()
+	if err != nil {
+		return "", nil, err
+	}
+
+	q := i.parent
+	var sb strings.Builder
+	var args []interface{}
+
+	switch q.caps().Upsert {
+	case UpsertOnConflict:
+		i.writeValues(&sb, &args)
+		sb.WriteString(fmt.Sprintf(" ON CONFLICT (%s)", strings.Join(q.quoteIdents(i.upsert.keys), ", ")))
+		if len(update) == 0 {
+			sb.WriteString(" DO NOTHING")
+			break
+		}
+		var sets []string
+		for _, c := range update {
+			sets = append(sets, fmt.Sprintf("%s = EXCLUDED.%s", q.quoteIdent(c), q.quoteIdent(c)))
+		}
+		sb.WriteString(" DO UPDATE SET " + strings.Join(sets, ", "))
+
+	case UpsertOnDuplicateKey:
+		i.writeValues(&sb, &args)
+		var sets []string
+		for _, c := range update {
+			sets = append(sets, fmt.Sprintf("%s = VALUES(%s)", q.quoteIdent(c), q.quoteIdent(c)))
+		}
+		if len(sets) == 0 {
+			// Assigning a key to itself is the MySQL idiom for "do nothing".
+			k := q.quoteIdent(i.upsert.keys[0])
+			sets = append(sets, fmt.Sprintf("%s = %s", k, k))
+		}
+		sb.WriteString(" ON DUPLICATE KEY UPDATE " + strings.Join(sets, ", "))
+
+	case UpsertMerge:
+		i.writeMerge(&sb, &args, update)
+
+	default:
+		return "", nil, fmt.Errorf("dialect %T does not support upsert", q.dialect)
+	}
+	return sb.String(), args, nil
+}
+
+// writeMerge renders an Oracle-style MERGE statement for the insert rows.
+func (i *InsertQuery) writeMerge(sb *strings.Builder, args *[]interface{}, update []string) {
+	q := i.parent
+	tgt, src := q.quoteIdent("tgt"), q.quoteIdent("src")
+
+	var selects []string
+	for _, row := range i.rows {
+		var cols []string
+		for n, v := range row {
+			*args = append(*args, v)
+			cols = append(cols, fmt.Sprintf("%s %s", q.dialect.Placeholder(len(*args)), q.quoteIdent(i.columns[n])))
+		}
+		selects = append(selects, fmt.Sprintf("SELECT %s FROM dual", strings.Join(cols, ", ")))
+	}
+	sb.WriteString(fmt.Sprintf("MERGE INTO %s %s USING (%s) %s",
+		q.quoteIdent(i.table), tgt, strings.Join(selects, " UNION ALL "), src))
+
+	var on []string
+	for _, k := range i.upsert.keys {
+		on = append(on, fmt.Sprintf("%s.%s = %s.%s", tgt, q.quoteIdent(k), src, q.quoteIdent(k)))
+	}
+	sb.WriteString(" ON (" + strings.Join(on, " AND ") + ")")
+
+	if len(update) > 0 {
+		var sets []string
+		for _, c := range update {
+			sets = append(sets, fmt.Sprintf("%s.%s = %s.%s", tgt, q.quoteIdent(c), src, q.quoteIdent(c)))
+		}
+		sb.WriteString(" WHEN MATCHED THEN UPDATE SET " + strings.Join(sets, ", "))
+	}
+
+	var values []string
+	for _, c := range i.columns {
+		values = append(values, src+"."+q.quoteIdent(c))
+	}
+	sb.WriteString(fmt.Sprintf(" WHEN NOT MATCHED THEN INSERT (%s) VALUES (%s)",
+		strings.Join(i.quotedColumns(), ", "), strings.Join(values, ", ")))
+}
+
+// quoteIdents quotes each identifier in names.
+func (q *Query) quoteIdents(names []string) []string {
+	quoted := make([]string, len(names))
+	for n, name := range names {
+		quoted[n] = q.quoteIdent(name)
+	}
+	return quoted
+}
@@ -0,0 +1,77 @@
+package query_builder
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestUpsert(t *testing.T) {
+	// users inserts two rows keyed on email.
+	users := func(d Dialect) *InsertQuery {
+		return New(d).Insert("users").Columns("email", "name", "age").Values("a@x", "ann", 30).Values("b@x", "bob", 17).OnConflict("email")
+	}
+	args := []interface{}{"a@x", "ann", 30, "b@x", "bob", 17}
+	tests := []struct {
+		name string
+		q    *InsertQuery
+		want string
+	}{
+		{"postgres update all", users(PostgresDialect{}),
+			`INSERT INTO "users" ("email", "name", "age") VALUES ($1, $2, $3), ($4, $5, $6) ON CONFLICT ("email") DO UPDATE SET "name" = EXCLUDED."name", "age" = EXCLUDED."age"`},
+		{"postgres update some", users(PostgresDialect{}).DoUpdate("age"),
+			`INSERT INTO "users" ("email", "name", "age") VALUES ($1, $2, $3), ($4, $5, $6) ON CONFLICT ("email") DO UPDATE SET "age" = EXCLUDED."age"`},
+		{"postgres do nothing", users(PostgresDialect{}).DoNothing(),
+			`INSERT INTO "users" ("email", "name", "age") VALUES ($1, $2, $3), ($4, $5, $6) ON CONFLICT ("email") DO NOTHING`},
+		{"sqlite", users(SQLiteDialect{}).DoUpdate("name"),
+			`INSERT INTO "users" ("email", "name", "age") VALUES (?, ?, ?), (?, ?, ?) ON CONFLICT ("email") DO UPDATE SET "name" = EXCLUDED."name"`},
+		{"mysql update all", users(MySQLDialect{}),
+			"INSERT INTO `users` (`email`, `name`, `age`) VALUES (?, ?, ?), (?, ?, ?) ON DUPLICATE KEY UPDATE `name` = VALUES(`name`), `age` = VALUES(`age`)"},
+		{"mysql do nothing", users(MySQLDialect{}).DoNothing(),
+			"INSERT INTO `users` (`email`, `name`, `age`) VALUES (?, ?, ?), (?, ?, ?) ON DUPLICATE KEY UPDATE `email` = `email`"},
+		{"oracle merge", users(OracleDialect{}).DoUpdate("name"),
+			`MERGE INTO "users" "tgt" USING (SELECT :1 "email", :2 "name", :3 "age" FROM dual UNION ALL SELECT :4 "email", :5 "name", :6 "age" FROM dual) "src" ON ("tgt"."email" = "src"."email") WHEN MATCHED THEN UPDATE SET "tgt"."name" = "src"."name" WHEN NOT MATCHED THEN INSERT ("email", "name", "age") VALUES ("src"."email", "src"."name", "src"."age")`},
+		{"oracle merge do nothing", users(OracleDialect{}).DoNothing(),
+			`MERGE INTO "users" "tgt" USING (SELECT :1 "email", :2 "name", :3 "age" FROM dual UNION ALL SELECT :4 "email", :5 "name", :6 "age" FROM dual) "src" ON ("tgt"."email" = "src"."email") WHEN NOT MATCHED THEN INSERT ("email", "name", "age") VALUES ("src"."email", "src"."name", "src"."age")`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sql, got, err := tt.q.Build()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if sql != tt.want {
+				t.Errorf("sql =\n%s\nwant\n%s", sql, tt.want)
+			}
+			if !reflect.DeepEqual(got, args) {
+				t.Errorf("args = %#v, want %#v", got, args)
+			}
+		})
+	}
+}
+
+func TestUpsertErrors(t *testing.T) {
+	insert := func(d Dialect) *InsertQuery {
+		return New(d).Insert("users").Columns("email", "name").Values("a@x", "ann")
+	}
+	tests := []struct {
+		name string
+		q    *InsertQuery
+		want string
+	}{
+		{"no keys", insert(PostgresDialect{}).DoUpdate("name"), "upsert conflict columns required"},
+		{"key not inserted", insert(PostgresDialect{}).OnConflict("id"), "upsert conflict column not inserted: id"},
+		{"update not inserted", insert(PostgresDialect{}).OnConflict("email").DoUpdate("age"), "upsert update column not inserted: age"},
+		{"update key", insert(PostgresDialect{}).OnConflict("email").DoUpdate("email"), "upsert cannot update conflict column: email"},
+		{"update and nothing", insert(PostgresDialect{}).OnConflict("email").DoUpdate("name").DoNothing(), "upsert cannot combine DoUpdate and DoNothing"},
+		{"unsupported", insert(SQLServerDialect{}).OnConflict("email"), "does not support upsert"},
+		{"old sqlite", insert(SQLiteDialect{Version: "3.23.1"}).OnConflict("email"), "does not support upsert"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, _, err := tt.q.Build(); err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Build error = %v, want %q", err, tt.want)
+			}
+		})
+	}
+}